@@ -0,0 +1,843 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/config"
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+	"github.com/ulule/limiter/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// noLimits is a limiter.Store that never limits requests
+type noLimits struct{}
+
+func (noLimits) Get(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	return limiter.Context{Limit: rate.Limit, Remaining: rate.Limit}, nil
+}
+
+func (l noLimits) Peek(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	return l.Get(ctx, key, rate)
+}
+
+func (l noLimits) Reset(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	return l.Get(ctx, key, rate)
+}
+
+// newTestStore loads the testdata fixtures of each collection, the
+// fixtures of the collections listed in files replace the defaults
+func newTestStore(t *testing.T, files map[string]string) *store.Memory {
+	t.Helper()
+
+	m := store.NewMemory()
+	for _, collection := range []string{"global", "greece", "gr_vaccines"} {
+		name, ok := files[collection]
+		if !ok {
+			name = collection + ".json"
+		}
+		f, err := os.Open(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = m.LoadJSON(collection, f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+// newTestAPI creates the API over the store, with in-memory limits
+// and cache stores
+func newTestAPI(t *testing.T, s store.Store) http.Handler {
+	t.Helper()
+	return NewAPI(config.New(), s, noLimits{}, persistence.NewInMemoryStore(time.Minute), zap.NewNop())
+}
+
+// request serves a request, the body is posted if not empty
+func request(h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// get serves a GET request, checking the response status
+func get(t *testing.T, h http.Handler, url string, status int) *httptest.ResponseRecorder {
+	t.Helper()
+	w := request(h, http.MethodGet, url, "")
+	if w.Code != status {
+		t.Fatalf("GET %s: status %d, want %d: %s", url, w.Code, status, w.Body.String())
+	}
+	return w
+}
+
+// decode decodes a json response body
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+}
+
+// entries decodes a json list response
+func entries(t *testing.T, w *httptest.ResponseRecorder) []map[string]interface{} {
+	t.Helper()
+	var list []map[string]interface{}
+	decode(t, w, &list)
+	return list
+}
+
+// numbers returns the numbers of a json series
+func numbers(t *testing.T, v interface{}) []interface{} {
+	t.Helper()
+	list, ok := v.([]interface{})
+	if !ok {
+		t.Fatalf("expected a series, got %v", v)
+	}
+	return list
+}
+
+func TestList(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	tests := []struct {
+		url   string
+		count int
+		check func(t *testing.T, list []map[string]interface{})
+	}{
+		{
+			// the latest date by default
+			url:   "/global",
+			count: 2,
+			check: func(t *testing.T, list []map[string]interface{}) {
+				for _, e := range list {
+					if e["date"] != "2020-12-09T00:00:00Z" {
+						t.Errorf("date = %v, want the latest date", e["date"])
+					}
+				}
+			},
+		},
+		{
+			url:   "/global/grc/cases/2020-12-08",
+			count: 2,
+			check: func(t *testing.T, list []map[string]interface{}) {
+				if list[0]["cases"] != 117000.0 || list[1]["cases"] != 118045.0 {
+					t.Errorf("cases = %v, %v", list[0]["cases"], list[1]["cases"])
+				}
+				if _, ok := list[0]["deaths"]; ok {
+					t.Errorf("unrequested keys returned: %v", list[0])
+				}
+			},
+		},
+		{
+			url:   "/global?country=GRC,ITA&keys=new_cases&from=2020-12-09",
+			count: 2,
+		},
+		{
+			url:   "/global?exclude=ITA&from=2020-12-01&to=2020-12-31",
+			count: 2,
+		},
+		{
+			url:   "/greece/EL122/new_cases/2020-12-08/2020-12-08",
+			count: 1,
+			check: func(t *testing.T, list []map[string]interface{}) {
+				if list[0]["new_cases"] != 500.0 {
+					t.Errorf("new_cases = %v, want 500", list[0]["new_cases"])
+				}
+			},
+		},
+		{
+			// vaccines return every date from their DefaultFrom
+			url:   "/vaccines/greece",
+			count: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			list := entries(t, get(t, h, tt.url, http.StatusOK))
+			if len(list) != tt.count {
+				t.Fatalf("got %d entries, want %d: %v", len(list), tt.count, list)
+			}
+			if tt.check != nil {
+				tt.check(t, list)
+			}
+		})
+	}
+}
+
+func TestListIDs(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/global/grc,ita/iso3/2020-12-09", "GRC,ITA"},
+		{"/global/all/iso3/2020-12-09?country=ita,%20grc", "GRC,ITA"},
+		{"/global/all/iso3/2020-12-09?exclude=grc", "ITA"},
+		{"/greece/el111,EL002/uid/2020-12-09", "EL002,EL111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			var ids []string
+			for _, e := range entries(t, get(t, h, tt.url, http.StatusOK)) {
+				for _, key := range []string{"iso3", "uid"} {
+					if id, ok := e[key].(string); ok {
+						ids = append(ids, id)
+					}
+				}
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("ids = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPages(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	var ids []string
+	url := "/global/all/iso3/2020-12-01?limit=3"
+	for page := 0; url != ""; page++ {
+		if page > 2 {
+			t.Fatal("too many pages")
+		}
+		w := get(t, h, url, http.StatusOK)
+		if exposed := w.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "X-Next-Cursor") || !strings.Contains(exposed, "Link") {
+			t.Errorf("Access-Control-Expose-Headers = %s, want the paging headers", exposed)
+		}
+		for _, e := range entries(t, w) {
+			ids = append(ids, e["iso3"].(string))
+		}
+		url = ""
+		if cursor := w.Header().Get("X-Next-Cursor"); cursor != "" {
+			url = "/global/all/iso3/2020-12-01?limit=3&cursor=" + cursor
+		}
+	}
+	if got := strings.Join(ids, ","); got != "GRC,ITA,GRC,ITA" {
+		t.Errorf("paged ids = %s, want GRC,ITA,GRC,ITA", got)
+	}
+}
+
+// countingCache counts the responses stored in the cache
+type countingCache struct {
+	persistence.CacheStore
+	sets int
+}
+
+func (c *countingCache) Set(key string, value interface{}, expire time.Duration) error {
+	c.sets++
+	return c.CacheStore.Set(key, value, expire)
+}
+
+func TestListStreamNotCached(t *testing.T) {
+	cache := &countingCache{CacheStore: persistence.NewInMemoryStore(time.Minute)}
+	h := NewAPI(config.New(), newTestStore(t, nil), noLimits{}, cache, zap.NewNop())
+
+	w := get(t, h, "/global?format=csv&from=2020-12-08", http.StatusOK)
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 5 {
+		t.Errorf("got %d csv lines, want the header and 4 rows", lines)
+	}
+	if cache.sets != 0 {
+		t.Errorf("streamed csv stored %d times in the cache", cache.sets)
+	}
+
+	get(t, h, "/global?format=csv&from=2020-12-08&limit=10", http.StatusOK)
+	if cache.sets != 1 {
+		t.Errorf("paged csv stored %d times in the cache, want once", cache.sets)
+	}
+}
+
+func TestAgg(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	list := entries(t, get(t, h, "/agg/global/GRC/new_cases,cases/2020-12-08", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	e := list[0]
+	if e["iso3"] != "GRC" || e["population"] != 10423056.0 {
+		t.Errorf("meta = %v", e)
+	}
+	if dates := numbers(t, e["date"]); len(dates) != 2 || dates[0] != "2020-12-08T00:00:00Z" {
+		t.Errorf("date = %v", dates)
+	}
+	if values := numbers(t, e["new_cases"]); len(values) != 2 || values[0] != 1000.0 || values[1] != 1045.0 {
+		t.Errorf("new_cases = %v", values)
+	}
+
+	// the default dataset under /agg
+	list = entries(t, get(t, h, "/agg?from=2020-12-08", http.StatusOK))
+	if len(list) != 2 || list[0]["iso3"] != "GRC" || list[1]["iso3"] != "ITA" {
+		t.Errorf("got %v, want GRC and ITA", list)
+	}
+
+	list = entries(t, get(t, h, "/agg/greece/all/new_cases/2020-12-08", http.StatusOK))
+	if len(list) != 3 {
+		t.Errorf("got %d regions, want 3", len(list))
+	}
+}
+
+func TestAggTestingMetrics(t *testing.T) {
+	// tests are reported from the second day, rapid tests from the third
+	h := newTestAPI(t, newTestStore(t, map[string]string{"global": "global_tests.json"}))
+
+	list := entries(t, get(t, h, "/agg/global/GRC/positivity_rate,rtpcr_share,rapid_share/2020-12-07", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	want := map[string][]interface{}{
+		"positivity_rate": {nil, 5.0, 5.0},
+		"rtpcr_share":     {nil, 80.0, 75.0},
+		"rapid_share":     {nil, nil, 25.0},
+	}
+	for key, values := range want {
+		got := numbers(t, list[0][key])
+		if !reflect.DeepEqual(got, values) {
+			t.Errorf("%s = %v, want %v", key, got, values)
+		}
+	}
+}
+
+func TestSumTestingMetrics(t *testing.T) {
+	s := newTestStore(t, map[string]string{"global": "global_tests.json"})
+	// a day without cases or rapid tests
+	err := s.Load("global", bson.M{
+		"date": time.Date(2020, 12, 9, 0, 0, 0, 0, time.UTC), "iso3": "CYP", "population": 875899,
+		"new_cases": 0, "new_tests": 1000, "new_tests_rapid": 0, "source": "imedd",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestAPI(t, s)
+
+	tests := []struct {
+		url  string
+		want map[string]interface{}
+	}{
+		{
+			// zero sums are values
+			url:  "/total/global/CYP/2020-12-09?keys=positivity_rate,rapid_share",
+			want: map[string]interface{}{"cases": 0.0, "tests_rapid": 0.0, "positivity_rate": 0.0, "rapid_share": 0.0},
+		},
+		{
+			// keys never reported sum to null
+			url:  "/total/global/GRC/2020-12-08/2020-12-08?keys=positivity_rate,rapid_share",
+			want: map[string]interface{}{"cases": 1000.0, "tests_rapid": nil, "positivity_rate": 5.0, "rapid_share": nil},
+		},
+	}
+	for _, tt := range tests {
+		list := entries(t, get(t, h, tt.url, http.StatusOK))
+		if len(list) != 1 {
+			t.Fatalf("%s: got %d entries, want 1", tt.url, len(list))
+		}
+		for key, want := range tt.want {
+			if got, ok := list[0][key]; !ok || got != want {
+				t.Errorf("%s: %s = %v, want %v", tt.url, key, got, want)
+			}
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	list := entries(t, get(t, h, "/total/global/GRC/2020-12-08", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	e := list[0]
+	if e["total_cases"] != 118045.0 || e["cases"] != 2045.0 || e["tests"] != 50050.0 {
+		t.Errorf("totals = %v", e)
+	}
+	// totals include every key
+	if v, ok := e["total_recovered"]; !ok || v != nil {
+		t.Errorf("total_recovered = %v, %v, want null", v, ok)
+	}
+
+	// the latest date by default
+	w := get(t, h, "/total/global", http.StatusOK)
+	list = entries(t, w)
+	if len(list) != 2 || list[0]["cases"] != 1045.0 {
+		t.Errorf("got %v, want the sums of the latest date", list)
+	}
+	if date := w.Header().Get("X-Data-Date"); date != "2020-12-09" {
+		t.Errorf("X-Data-Date = %s, want 2020-12-09", date)
+	}
+	if exposed := w.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "X-Data-Date") {
+		t.Errorf("Access-Control-Expose-Headers = %s, want X-Data-Date", exposed)
+	}
+}
+
+func TestGroupRegions(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	// the imported cases are a separate area
+	list := entries(t, get(t, h, "/total/greece/all/2020-12-08?group_by=state", http.StatusOK))
+	var got []interface{}
+	for _, e := range list {
+		if e["uid"] != nil || e["region"] != nil {
+			t.Errorf("area with regional unit keys: %v", e)
+		}
+		got = append(got, e["state"], e["cases"], e["population"])
+	}
+	want := []interface{}{
+		"-", 3.0, 0.0,
+		"Central Macedonia", 1145.0, 1110551.0,
+		"East Macedonia-Thrace", 97.0, 147947.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// the country includes the imported cases, but not in its population
+	list = entries(t, get(t, h, "/total/greece/all/2020-12-08?group_by=country", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	e := list[0]
+	if e["uid"] != "EL" || e["cases"] != 1245.0 || e["total_cases"] != 28513.0 || e["population"] != 1258498.0 {
+		t.Errorf("country = %v", e)
+	}
+
+	list = entries(t, get(t, h, "/agg/greece/all/new_cases/2020-12-08?group_by=country", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	if values, want := numbers(t, list[0]["new_cases"]), []interface{}{541.0, 704.0}; !reflect.DeepEqual(values, want) {
+		t.Errorf("new_cases = %v, want %v", values, want)
+	}
+}
+
+func TestGroupCountries(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	list := entries(t, get(t, h, "/total/global/all/2020-12-08?group_by=continent", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	e := list[0]
+	if e["group"] != "Europe" || e["cases"] != 26800.0 || e["population"] != 70884884.0 {
+		t.Errorf("continent = %v", e)
+	}
+
+	list = entries(t, get(t, h, "/agg/global/all/new_cases/2020-12-08?group_by=who_region", http.StatusOK))
+	if len(list) != 1 || list[0]["group"] != "EURO" {
+		t.Fatalf("got %v, want EURO", list)
+	}
+	if values, want := numbers(t, list[0]["new_cases"]), []interface{}{13000.0, 13800.0}; !reflect.DeepEqual(values, want) {
+		t.Errorf("new_cases = %v, want %v", values, want)
+	}
+
+	// regions are matched case insensitively
+	for url, want := range map[string]int{
+		"/total/global?region=eu":   2,
+		"/agg/global?region=Europe": 2,
+		"/global?region=asia":       0,
+	} {
+		if list := entries(t, get(t, h, url, http.StatusOK)); len(list) != want {
+			t.Errorf("%s: got %d entries, want %d", url, len(list), want)
+		}
+	}
+}
+
+func TestVaccineCoverage(t *testing.T) {
+	s := newTestStore(t, nil)
+	// a region of unknown population
+	err := s.Load("gr_vaccines", bson.M{
+		"date": time.Date(2021, 3, 6, 0, 0, 0, 0, time.UTC), "uid": "PE9999", "region": "Unknown",
+		"total_dose_1": 100, "total_dose_2": 50, "total_dose_3": 10, "source": "govgr",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestAPI(t, s)
+
+	list := entries(t, get(t, h, "/total/vaccines/greece/all?keys=coverage_dose_1,coverage_full,coverage_booster,dose_2_completion,booster_uptake", http.StatusOK))
+	if len(list) != 3 {
+		t.Fatalf("got %d entries, want 3", len(list))
+	}
+	want := map[string][]interface{}{
+		"PE1001": {5.2553, 2.1124, 0.0, 40.1961, 0.0},
+		"PE202":  {5.4027, 2.7014, 0.0, 50.0, 0.0},
+		"PE9999": {nil, nil, nil, 50.0, 20.0},
+	}
+	for _, e := range list {
+		var got []interface{}
+		for _, key := range []string{"coverage_dose_1", "coverage_full", "coverage_booster", "dose_2_completion", "booster_uptake"} {
+			if v, ok := e[key].(float64); ok {
+				got = append(got, math.Round(v*1e4)/1e4)
+			} else {
+				got = append(got, e[key])
+			}
+		}
+		uid, _ := e["uid"].(string)
+		if !reflect.DeepEqual(got, want[uid]) {
+			t.Errorf("%s = %v, want %v", uid, got, want[uid])
+		}
+		// unknown populations are told apart from missing vaccinations
+		if known := e["population_known"]; known != (uid != "PE9999") {
+			t.Errorf("%s population_known = %v", uid, known)
+		}
+	}
+
+	list = entries(t, get(t, h, "/agg/vaccines/greece/PE1001/coverage_full/2021-03-05", http.StatusOK))
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	// coverage is returned along with the population
+	if list[0]["population"] != 97044.0 {
+		t.Errorf("population = %v", list[0]["population"])
+	}
+	values := numbers(t, list[0]["coverage_full"])
+	if len(values) != 2 || math.Abs(values[0].(float64)-2.0609) > 1e-4 || math.Abs(values[1].(float64)-2.1124) > 1e-4 {
+		t.Errorf("coverage_full = %v", values)
+	}
+	if list[0]["population_known"] != true {
+		t.Errorf("population_known = %v, want true", list[0]["population_known"])
+	}
+
+	list = entries(t, get(t, h, "/vaccines/greece/PE9999/coverage_full/2021-03-06", http.StatusOK))
+	if len(list) != 1 || list[0]["coverage_full"] != nil || list[0]["population_known"] != false {
+		t.Errorf("got %v, want null coverage of an unknown population", list)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	s := newTestStore(t, nil)
+	day := func(n int) time.Time { return time.Date(2021, 3, 5+n, 0, 0, 0, 0, time.UTC) }
+	var cases, vaccines []interface{}
+	for i := 0; i < 2; i++ {
+		cases = append(cases,
+			bson.M{"date": day(i), "uid": "EL122", "region": "Thessaloniki", "population": 1110551, "new_cases": 300 + i, "source": "imedd"},
+			bson.M{"date": day(i), "uid": "EL411", "region": "Lesvos", "population": 100000, "new_cases": 10 + i, "source": "imedd"},
+		)
+	}
+	// Lemnos is merged to Lesvos, carrying its totals over the second day
+	vaccines = append(vaccines,
+		bson.M{"date": day(0), "uid": "PE1101", "region": "Lesvos", "population": 80000, "total_dose_1": 8000, "total_dose_2": 4000, "source": "govgr"},
+		bson.M{"date": day(1), "uid": "PE1101", "region": "Lesvos", "population": 80000, "total_dose_1": 9000, "total_dose_2": 4500, "source": "govgr"},
+		bson.M{"date": day(0), "uid": "PE1102", "region": "Lemnos", "population": 20000, "total_dose_1": 2000, "total_dose_2": 1000, "source": "govgr"},
+	)
+	if err := s.Load("greece", cases...); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load("gr_vaccines", vaccines...); err != nil {
+		t.Fatal(err)
+	}
+	h := newTestAPI(t, s)
+
+	list := entries(t, get(t, h, "/join/greece/vaccines/all/2021-03-05/2021-03-06", http.StatusOK))
+	if len(list) != 2 {
+		t.Fatalf("got %d entries, want 2", len(list))
+	}
+	tests := []struct {
+		uid  string
+		ids  []interface{}
+		want map[string][]interface{}
+	}{
+		{
+			uid: "EL122",
+			ids: []interface{}{"PE202"},
+			want: map[string][]interface{}{
+				"new_cases":     {300.0, 301.0},
+				"coverage_full": {nil, 2.7014},
+			},
+		},
+		{
+			uid: "EL411",
+			ids: []interface{}{"PE1101", "PE1102"},
+			want: map[string][]interface{}{
+				"new_cases":       {10.0, 11.0},
+				"coverage_dose_1": {10.0, 11.0},
+				"coverage_full":   {5.0, 5.5},
+			},
+		},
+	}
+	for i, tt := range tests {
+		e := list[i]
+		if e["uid"] != tt.uid {
+			t.Fatalf("uid = %v, want %s", e["uid"], tt.uid)
+		}
+		if ids := numbers(t, e["vaccines_ids"]); !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%s vaccines_ids = %v, want %v", tt.uid, ids, tt.ids)
+		}
+		for key, want := range tt.want {
+			var got []interface{}
+			for _, v := range numbers(t, e[key]) {
+				if f, ok := v.(float64); ok {
+					v = math.Round(f*1e4) / 1e4
+				}
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %s = %v, want %v", tt.uid, key, got, want)
+			}
+		}
+		// two dates are too few to correlate
+		for _, c := range numbers(t, e["correlation"]) {
+			if c := c.(map[string]interface{}); c["r"] != nil {
+				t.Errorf("%s correlation = %v, want null", tt.uid, c)
+			}
+		}
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	// a polygon around Greece
+	body := `{"type":"Polygon","coordinates":[[[19,34],[30,34],[30,42],[19,42],[19,34]]]}`
+	w := request(h, http.MethodPost, "/global?keys=iso3&from=2020-12-09", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if list := entries(t, w); len(list) != 1 || list[0]["iso3"] != "GRC" {
+		t.Errorf("got %v, want GRC", list)
+	}
+
+	for _, body := range []string{
+		`{"type":"Polygon","coordinates":[[[1],[2],[1]]]}`,
+		`{"type":"Polygon","coordinates":[[[19,34],[30,34]]]}`,
+		`{"type":"Polygon","coordinates":[[[19,34],[300,34],[30,42]]]}`,
+		`{"type":"Point","coordinates":[19,34]}`,
+	} {
+		w := request(h, http.MethodPost, "/global", body)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"polygon"`) {
+			t.Errorf("POST %s: status %d, want an invalid polygon: %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	w = request(h, http.MethodOptions, "/global", "")
+	if methods := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(methods, "POST") {
+		t.Errorf("Access-Control-Allow-Methods = %s, want POST", methods)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	// features are located even if the keys leave out loc
+	for _, url := range []string{
+		"/global/GRC/cases/2020-12-08?format=geojson",
+		"/total/global/GRC/2020-12-08?format=geojson&keys=cases",
+		"/trends/global/GRC?format=geojson",
+	} {
+		var fc struct {
+			Type     string `json:"type"`
+			Features []struct {
+				Geometry   *struct{ Type string } `json:"geometry"`
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"features"`
+		}
+		decode(t, get(t, h, url, http.StatusOK), &fc)
+		if fc.Type != "FeatureCollection" || len(fc.Features) == 0 {
+			t.Fatalf("%s: got %+v, want features", url, fc)
+		}
+		for _, f := range fc.Features {
+			if f.Geometry == nil || f.Geometry.Type != "Point" {
+				t.Errorf("%s: geometry = %v, want a point", url, f.Geometry)
+			}
+			if _, ok := f.Properties["loc"]; ok {
+				t.Errorf("%s: loc returned in the properties", url)
+			}
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	for _, url := range []string{
+		"/v2/global",
+		// the sources are not among the requested keys
+		"/v2/global/all/new_cases",
+		"/global/all/new_cases?envelope=true",
+		"/v2/agg/global/all/new_cases",
+	} {
+		t.Run(url, func(t *testing.T) {
+			var body struct {
+				Meta struct {
+					Count         int       `json:"count"`
+					Sources       []string  `json:"sources"`
+					LastUpdatedAt time.Time `json:"last_updated_at"`
+				} `json:"meta"`
+				Data []map[string]interface{} `json:"data"`
+			}
+			decode(t, get(t, h, url, http.StatusOK), &body)
+			if body.Meta.Count != 2 || len(body.Data) != 2 {
+				t.Errorf("count = %d, got %d entries, want 2", body.Meta.Count, len(body.Data))
+			}
+			if got := strings.Join(body.Meta.Sources, ","); got != "imedd,jhu" {
+				t.Errorf("sources = %s, want imedd,jhu", got)
+			}
+			if want := time.Date(2020, 12, 10, 10, 0, 0, 0, time.UTC); !body.Meta.LastUpdatedAt.Equal(want) {
+				t.Errorf("last_updated_at = %v, want %v", body.Meta.LastUpdatedAt, want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeServed(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	tests := []struct {
+		url     string
+		from    string
+		to      string
+		sources string
+		updated time.Time
+	}{
+		// totals have no dates, the dates of the documents summed
+		{"/v2/total/global/GRC/2020-12-08", "2020-12-08", "2020-12-09", "imedd", time.Date(2020, 12, 10, 10, 0, 0, 0, time.UTC)},
+		{"/v2/global/GRC/new_cases/2020-12-08", "2020-12-08", "2020-12-09", "imedd", time.Date(2020, 12, 10, 10, 0, 0, 0, time.UTC)},
+		// the documents of the page served
+		{"/v2/global/all/new_cases/2020-12-08?limit=1", "2020-12-08", "2020-12-08", "imedd", time.Date(2020, 12, 9, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			var body struct {
+				Meta struct {
+					From          *time.Time `json:"from"`
+					To            *time.Time `json:"to"`
+					Sources       []string   `json:"sources"`
+					LastUpdatedAt time.Time  `json:"last_updated_at"`
+				} `json:"meta"`
+			}
+			decode(t, get(t, h, tt.url, http.StatusOK), &body)
+			if body.Meta.From == nil || body.Meta.From.Format("2006-01-02") != tt.from {
+				t.Errorf("from = %v, want %s", body.Meta.From, tt.from)
+			}
+			if body.Meta.To == nil || body.Meta.To.Format("2006-01-02") != tt.to {
+				t.Errorf("to = %v, want %s", body.Meta.To, tt.to)
+			}
+			if got := strings.Join(body.Meta.Sources, ","); got != tt.sources {
+				t.Errorf("sources = %s, want %s", got, tt.sources)
+			}
+			if !body.Meta.LastUpdatedAt.Equal(tt.updated) {
+				t.Errorf("last_updated_at = %v, want %v", body.Meta.LastUpdatedAt, tt.updated)
+			}
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	cfg := config.New()
+	cfg.Legacy.Deprecation = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	cfg.Legacy.Sunset = time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)
+	h := NewAPI(cfg, newTestStore(t, nil), noLimits{}, persistence.NewInMemoryStore(time.Minute), zap.NewNop())
+
+	tests := []struct {
+		url        string
+		envelope   bool
+		deprecated bool
+	}{
+		{"/global", false, true},
+		{"/v1/global", false, false},
+		{"/v2/global", true, false},
+		{"/v2/global?envelope=false", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			w := get(t, h, tt.url, http.StatusOK)
+			if envelope := strings.HasPrefix(w.Body.String(), `{"meta":`); envelope != tt.envelope {
+				t.Errorf("enveloped = %v, want %v: %s", envelope, tt.envelope, w.Body.String())
+			}
+
+			link := w.Header().Get("Link")
+			deprecated := w.Header().Get("Deprecation") != "" && w.Header().Get("Sunset") != ""
+			if deprecated != tt.deprecated || tt.deprecated != strings.Contains(link, `</v1/global>; rel="successor-version"`) {
+				t.Errorf("Deprecation = %q, Sunset = %q, Link = %q", w.Header().Get("Deprecation"), w.Header().Get("Sunset"), link)
+			}
+			exposed := w.Header().Get("Access-Control-Expose-Headers")
+			for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+				if !strings.Contains(exposed, header) {
+					t.Errorf("Access-Control-Expose-Headers = %s, want %s", exposed, header)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionsPageLinks(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	url := "/global/all/iso3/2020-12-01?limit=3"
+	// the cached response replays both links
+	for _, replay := range []bool{false, true} {
+		w := get(t, h, url, http.StatusOK)
+		link := w.Header().Get("Link")
+		if !strings.Contains(link, `</v1`+url+`>; rel="successor-version"`) || !strings.Contains(link, `rel="next"`) {
+			t.Errorf("replay %v: Link = %q, want the successor version and next page links", replay, link)
+		}
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	h := newTestAPI(t, newTestStore(t, nil))
+
+	tests := []struct {
+		url   string
+		param string
+	}{
+		{"/global/XXX", "country"},
+		{"/global/all/foo", "keys"},
+		{"/global/all/all/2020-13-01", "from"},
+		{"/agg/global/all/all/2020-12-09/2020-12-01", "from"},
+		{"/total/global?exclude=XXX", "exclude"},
+		{"/total/greece?group_by=municipality", "group_by"},
+		{"/agg/global?region=Atlantis", "region"},
+		{"/global/all/new_cases?transform=rolling_mean&limit=10", "transform"},
+		{"/global/all/new_cases?transform=rolling_sum&offset=2", "transform"},
+		{"/agg/global?limit=10", "limit"},
+		{"/total/global?offset=1", "offset"},
+		{"/trends/global?cursor=abc", "cursor"},
+		{"/rt/greece?limit=1", "limit"},
+		{"/quality/global?limit=1", "limit"},
+		{"/join/greece/vaccines?cursor=abc", "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			w := get(t, h, tt.url, http.StatusBadRequest)
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+				t.Errorf("Content-Type = %s", ct)
+			}
+			var p struct {
+				Status        int `json:"status"`
+				InvalidParams []struct {
+					Name string `json:"name"`
+				} `json:"invalid_params"`
+			}
+			decode(t, w, &p)
+			if p.Status != http.StatusBadRequest || len(p.InvalidParams) == 0 || p.InvalidParams[0].Name != tt.param {
+				t.Errorf("problem = %+v, want invalid %s", p, tt.param)
+			}
+		})
+	}
+
+	w := get(t, h, "/nope", http.StatusNotFound)
+	if !strings.Contains(w.Body.String(), "available_endpoints") {
+		t.Errorf("404 without the available endpoints: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,738 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
+	"github.com/cvcio/covid-19-api/pkg/config"
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Dataset Data Handlers
+type Dataset struct {
+	cfg    *config.Config
+	dbConn store.Store
+	log    *zap.SugaredLogger
+	ds     *dataset.Dataset
+}
+
+// NewDatasetHandler creates the appropriate handler
+func NewDatasetHandler(cfg *config.Config, db store.Store, logger *zap.Logger, ds *dataset.Dataset) *Dataset {
+	return &Dataset{
+		cfg:    cfg,
+		dbConn: db,
+		log:    logger.Sugar(),
+		ds:     ds,
+	}
+}
+
+// List Data, paged with the limit, offset and cursor query params
+func (h *Dataset) List(c *gin.Context) {
+	for _, name := range []string{"interval", "group_by", "fill"} {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by raw data, use the aggregated data", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV, FormatGeoJSON)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+	if f == FormatGeoJSON {
+		// features need the location of the records
+		opts = append(opts, dataset.Geometry(true))
+	}
+
+	// stream unpaged csv, paged lists are small enough to buffer
+	// and need the next page headers before the body
+	if Streamed(c) {
+		h.dateHeader(c)
+		w := newCSVWriter(c, h.ds.ListColumns(opts...))
+		if _, err := h.ds.Each(h.dbConn, w.Write, opts...); err != nil {
+			h.respondStream(c, err)
+			return
+		}
+		if err := w.Close(); err != nil {
+			h.log.Errorw("dataset csv write failed", "dataset", h.ds.Name, "error", err)
+		}
+		return
+	}
+
+	page, err := h.ds.Page(h.dbConn, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	// link to the next page
+	if page.Next != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Del("offset")
+		query.Set("cursor", page.Next)
+		next.RawQuery = query.Encode()
+
+		// joined with the successor version link of the legacy routes,
+		// the cached responses replay a single value of each header
+		link := fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI())
+		if prev := c.Writer.Header().Get("Link"); prev != "" {
+			link = prev + ", " + link
+		}
+		c.Header("Link", link)
+		c.Header("X-Next-Cursor", page.Next)
+	}
+
+	h.write(c, f, h.ds.ListColumns(opts...), page.Data, opts)
+}
+
+// pageParams are the List paging params, not supported by the
+// endpoints responding with a single entry per country or region
+var pageParams = []string{"limit", "offset", "cursor"}
+
+// Agg Aggregate Data
+func (h *Dataset) Agg(c *gin.Context) {
+	for _, name := range pageParams {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by aggregated data", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	if v := c.Query("quality"); v != "" {
+		quality, err := strconv.ParseBool(v)
+		if err != nil || quality && f == FormatCSV {
+			reason := "expected true or false"
+			if err == nil {
+				reason = "quality flags are not supported by csv"
+			}
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: "quality", Reason: reason, Values: []string{v},
+			}}})
+			return
+		}
+		opts = append(opts, dataset.Quality(quality))
+	}
+
+	res, err := h.ds.Agg(h.dbConn, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	h.write(c, f, h.ds.AggColumns(opts...), res, opts)
+}
+
+// Sum Data
+func (h *Dataset) Sum(c *gin.Context) {
+	if transform := c.Query("transform"); transform != "" {
+		h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+			Name: "transform", Reason: "transforms are not supported by totals", Values: []string{transform},
+		}}})
+		return
+	}
+	for _, name := range append([]string{"fill"}, pageParams...) {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by totals", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV, FormatGeoJSON)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	res, err := h.ds.Sum(h.dbConn, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	h.write(c, f, h.ds.SumColumns(opts...), res, opts)
+}
+
+// Trend Data, the latest trend metrics of each country or region
+func (h *Dataset) Trend(c *gin.Context) {
+	for _, name := range append([]string{"transform", "per", "interval"}, pageParams...) {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by trends", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	window, invalid := window(c)
+	if invalid != nil {
+		h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{*invalid}})
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV, FormatGeoJSON)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	res, err := h.ds.Trend(h.dbConn, window, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	h.write(c, f, h.ds.TrendColumns(), res, opts)
+}
+
+// Rt Data, the reproduction number estimates of each country or region
+func (h *Dataset) Rt(c *gin.Context) {
+	for _, name := range append([]string{"transform", "per", "interval"}, pageParams...) {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by rt", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	var invalid []dataset.InvalidParam
+	window, param := window(c)
+	if param != nil {
+		invalid = append(invalid, *param)
+	}
+
+	si := dataset.DefaultSerialInterval
+	if v := c.Query("si_mean"); v != "" {
+		if si.Mean, err = strconv.ParseFloat(v, 64); err != nil || si.Mean <= 1 {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "si_mean", Reason: "expected a serial interval mean over 1 day", Values: []string{v},
+			})
+		}
+	}
+	if v := c.Query("si_sd"); v != "" {
+		if si.SD, err = strconv.ParseFloat(v, 64); err != nil || si.SD <= 0 {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "si_sd", Reason: "expected a positive serial interval standard deviation", Values: []string{v},
+			})
+		}
+	}
+	if len(invalid) > 0 {
+		h.respond(c, nil, &dataset.ValidationError{Params: invalid})
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	res, err := h.ds.Rt(h.dbConn, si, window, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	h.write(c, f, h.ds.RtColumns(), res, opts)
+}
+
+// Quality Data, the data-quality issues of each country or region
+func (h *Dataset) Quality(c *gin.Context) {
+	for _, name := range append([]string{"transform", "per", "interval", "fill"}, pageParams...) {
+		if v := c.Query(name); v != "" {
+			h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+				Name: name, Reason: "not supported by quality reports", Values: []string{v},
+			}}})
+			return
+		}
+	}
+
+	opts, err := h.opts(c)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	f, err := format(c, FormatJSON, FormatCSV, FormatGeoJSON)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	res, err := h.ds.Quality(h.dbConn, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+
+	h.write(c, f, h.ds.QualityColumns(), res, opts)
+}
+
+// Join Data, the series of each region joined with the series of the
+// joined dataset
+func (h *Dataset) Join(j *dataset.Join) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, name := range append([]string{"transform", "per", "interval", "group_by", "fill", "quality"}, pageParams...) {
+			if v := c.Query(name); v != "" {
+				h.respond(c, nil, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+					Name: name, Reason: "not supported by joins", Values: []string{v},
+				}}})
+				return
+			}
+		}
+
+		opts, err := h.opts(c)
+		if err != nil {
+			h.respond(c, nil, err)
+			return
+		}
+
+		f, err := format(c, FormatJSON, FormatCSV, FormatGeoJSON)
+		if err != nil {
+			h.respond(c, nil, err)
+			return
+		}
+
+		res, err := h.ds.Join(h.dbConn, j, opts...)
+		if err != nil {
+			h.respond(c, nil, err)
+			return
+		}
+
+		h.write(c, f, h.ds.JoinColumns(j), res, opts)
+	}
+}
+
+// write writes the result in the requested format
+func (h *Dataset) write(c *gin.Context, f string, columns []string, res []interface{}, opts []func(*dataset.ListOptions)) {
+	h.dateHeader(c)
+
+	switch f {
+	case FormatGeoJSON:
+		if err := writeGeoJSON(c, res); err != nil {
+			h.respond(c, nil, err)
+		}
+		return
+	case FormatJSON:
+		if ok, _ := envelope(c, f); ok {
+			h.writeEnvelope(c, columns, res, opts)
+			return
+		}
+		h.respond(c, res, nil)
+		return
+	}
+
+	w := newCSVWriter(c, columns)
+	for _, entry := range res {
+		if err := w.Write(entry); err != nil {
+			h.respondStream(c, err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		h.log.Errorw("dataset csv write failed", "dataset", h.ds.Name, "error", err)
+	}
+}
+
+// writeEnvelope writes the result wrapped with its metadata
+func (h *Dataset) writeEnvelope(c *gin.Context, columns []string, res []interface{}, opts []func(*dataset.ListOptions)) {
+	meta, err := h.ds.NewMeta(h.dbConn, res, columns, opts...)
+	if err != nil {
+		h.respond(c, nil, err)
+		return
+	}
+	meta.Transforms = append(meta.Transforms, applied(c)...)
+
+	if res == nil {
+		res = []interface{}{}
+	}
+	c.JSON(http.StatusOK, &dataset.Envelope{Meta: meta, Data: res})
+}
+
+// respondStream responds with the error if nothing was written yet,
+// otherwise the response is aborted as the status is already sent
+func (h *Dataset) respondStream(c *gin.Context, err error) {
+	if !c.Writer.Written() {
+		h.respond(c, nil, err)
+		return
+	}
+	h.log.Errorw("dataset stream failed", "dataset", h.ds.Name, "error", err)
+	c.Abort()
+}
+
+// opts parses the route params and the query string to list options,
+// query string values take precedence over the positional route params
+func (h *Dataset) opts(c *gin.Context) ([]func(*dataset.ListOptions), error) {
+	opts := dataset.NewListOpts()
+	var invalid []dataset.InvalidParam
+
+	if id := param(c, h.ds.Param); id != "" && strings.ToUpper(id) != "ALL" {
+		opts = append(opts, dataset.IDs(strings.Split(id, ",")...))
+	}
+
+	if exclude := c.Query("exclude"); exclude != "" {
+		opts = append(opts, dataset.Exclude(strings.Split(exclude, ",")...))
+	}
+
+	if keys := param(c, "keys"); keys != "" {
+		opts = append(opts, dataset.Keys(keys))
+	}
+
+	if from := param(c, "from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "from", Reason: "invalid date, expected YYYY-MM-DD", Values: []string{from},
+			})
+		}
+		opts = append(opts, dataset.From(t))
+	}
+
+	if to := param(c, "to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "to", Reason: "invalid date, expected YYYY-MM-DD", Values: []string{to},
+			})
+		}
+		opts = append(opts, dataset.To(t))
+	}
+
+	if date := c.Query("date"); date != "" {
+		opts = append(opts, dataset.Date(date))
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "limit", Reason: "expected a positive integer", Values: []string{limit},
+			})
+		}
+		opts = append(opts, dataset.Limit(n))
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "offset", Reason: "expected a non-negative integer", Values: []string{offset},
+			})
+		}
+		opts = append(opts, dataset.Offset(n))
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		opts = append(opts, dataset.Cursor(cursor))
+	}
+
+	if near := c.Query("near"); near != "" {
+		p, err := floats(near, 2)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "near", Reason: "expected lon,lat", Values: []string{near},
+			})
+		}
+		radius, err := strconv.ParseFloat(c.Query("radius"), 64)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "radius", Reason: "expected a positive radius in kilometers", Values: []string{c.Query("radius")},
+			})
+		}
+		if len(p) == 2 {
+			opts = append(opts, dataset.Near(p[0], p[1], radius))
+		}
+	}
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		b, err := floats(bbox, 4)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "bbox", Reason: "expected min lon,min lat,max lon,max lat", Values: []string{bbox},
+			})
+		} else {
+			opts = append(opts, dataset.BBox(b[0], b[1], b[2], b[3]))
+		}
+	}
+
+	if c.Request.Method == http.MethodPost {
+		ring, err := polygon(c)
+		if err != nil {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "polygon", Reason: err.Error(),
+			})
+		} else {
+			opts = append(opts, dataset.Polygon(ring))
+		}
+	}
+
+	if transform := c.Query("transform"); transform != "" {
+		t, params := h.transforms(c, transform)
+		invalid = append(invalid, params...)
+		opts = append(opts, dataset.Transforms(t...))
+	}
+
+	if interval := c.Query("interval"); interval != "" {
+		opts = append(opts, dataset.Interval(strings.ToLower(interval)))
+	}
+
+	// datasets filtered by region name, e.g. `EU`
+	if region := c.Query("region"); region != "" && len(h.ds.Regions) > 0 {
+		opts = append(opts, dataset.Region(region))
+	}
+
+	if fill := c.Query("fill"); fill != "" {
+		opts = append(opts, dataset.Fill(strings.ToLower(fill)))
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		opts = append(opts, dataset.GroupBy(strings.ToLower(groupBy)))
+	}
+
+	if per := c.Query("per"); per != "" {
+		opts = append(opts, dataset.Per(strings.ToLower(per)))
+	}
+
+	if len(invalid) > 0 {
+		return nil, &dataset.ValidationError{Params: invalid}
+	}
+
+	// resolve the date selector once, the queries of the request
+	// read the resolved date without looking up the latest dates
+	day, err := h.ds.Resolve(h.dbConn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !day.IsZero() {
+		c.Set(dateKey, day)
+		opts = append(opts, dataset.Date(day.Format("2006-01-02")))
+	}
+	return opts, nil
+}
+
+// dateKey is the context key of the date the request resolves to
+const dateKey = "date"
+
+// dateHeader echoes the date requests without a date range resolve to
+func (h *Dataset) dateHeader(c *gin.Context) {
+	if day := c.GetTime(dateKey); !day.IsZero() {
+		c.Header("X-Data-Date", day.Format("2006-01-02"))
+	}
+}
+
+// transforms parses the rolling and trends transforms query params
+func (h *Dataset) transforms(c *gin.Context, transform string) ([]dataset.Transform, []dataset.InvalidParam) {
+	var invalid []dataset.InvalidParam
+
+	window, err := window(c)
+	if err != nil {
+		invalid = append(invalid, *err)
+	}
+
+	aligns := []string{dataset.AlignTrailing, dataset.AlignCentered}
+	align := c.DefaultQuery("align", dataset.AlignTrailing)
+	if !dataset.IsValidKey(align, aligns) {
+		invalid = append(invalid, dataset.InvalidParam{
+			Name: "align", Reason: "unsupported alignment", Values: []string{align}, Valid: aligns,
+		})
+	}
+
+	partials := []string{dataset.PartialNull, dataset.PartialShrink}
+	partial := c.DefaultQuery("partial", dataset.PartialNull)
+	if !dataset.IsValidKey(partial, partials) {
+		invalid = append(invalid, dataset.InvalidParam{
+			Name: "partial", Reason: "unsupported partial window handling", Values: []string{partial}, Valid: partials,
+		})
+	}
+
+	fns := []string{"rolling_" + dataset.RollingMean, "rolling_" + dataset.RollingSum}
+	if len(h.ds.Trends) > 0 {
+		fns = append(fns, "trends")
+	}
+	var transforms []dataset.Transform
+	for _, fn := range strings.Split(transform, ",") {
+		fn = strings.TrimSpace(fn)
+		if !dataset.IsValidKey(fn, fns) {
+			invalid = append(invalid, dataset.InvalidParam{
+				Name: "transform", Reason: "unsupported transform", Values: []string{fn}, Valid: fns,
+			})
+			continue
+		}
+		if fn == "trends" {
+			transforms = append(transforms, dataset.Growth(h.ds.Trends, window))
+			continue
+		}
+		transforms = append(transforms, dataset.Rolling(strings.TrimPrefix(fn, "rolling_"), window, align, partial))
+	}
+	return transforms, invalid
+}
+
+// applied returns the names of the transforms applied by the query
+// params, e.g. `rolling_mean_7` or `per_100k`
+func applied(c *gin.Context) []string {
+	var list []string
+	if transform := c.Query("transform"); transform != "" {
+		window, _ := window(c)
+		for _, fn := range strings.Split(transform, ",") {
+			fn = strings.TrimSpace(fn)
+			if strings.HasPrefix(fn, "rolling_") {
+				fn = fmt.Sprintf("%s_%d", fn, window)
+			}
+			list = append(list, fn)
+		}
+	}
+
+	for _, name := range []string{"interval", "fill", "group_by", "per"} {
+		v := strings.ToLower(c.Query(name))
+		if v == "" || name == "interval" && v == dataset.IntervalDay || name == "fill" && v == dataset.FillNone {
+			continue
+		}
+		list = append(list, name+"_"+v)
+	}
+	return list
+}
+
+// window parses the window query param, 7 days by default
+func window(c *gin.Context) (int, *dataset.InvalidParam) {
+	window, err := strconv.Atoi(c.DefaultQuery("window", "7"))
+	if err != nil || window < 1 {
+		return 0, &dataset.InvalidParam{
+			Name: "window", Reason: "expected a positive integer", Values: []string{c.Query("window")},
+		}
+	}
+	return window, nil
+}
+
+// floats parses a comma separated list of n numbers
+func floats(str string, n int) ([]float64, error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d numbers", n)
+	}
+
+	list := make([]float64, 0, n)
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// polygon reads the outer ring of the GeoJSON polygon, or polygon
+// feature, posted in the request body
+func polygon(c *gin.Context) ([][]float64, error) {
+	var body struct {
+		Type        string        `json:"type"`
+		Coordinates [][][]float64 `json:"coordinates"`
+		Geometry    *struct {
+			Type        string        `json:"type"`
+			Coordinates [][][]float64 `json:"coordinates"`
+		} `json:"geometry"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		return nil, errors.New("expected a GeoJSON polygon body")
+	}
+
+	if body.Type == "Feature" && body.Geometry != nil {
+		body.Type, body.Coordinates = body.Geometry.Type, body.Geometry.Coordinates
+	}
+	if body.Type != "Polygon" || len(body.Coordinates) == 0 {
+		return nil, errors.New("expected a GeoJSON polygon body")
+	}
+	ring := body.Coordinates[0]
+	if len(ring) < 3 {
+		return nil, errors.New("expected a GeoJSON polygon of at least 3 [lon, lat] positions")
+	}
+	for _, p := range ring {
+		if len(p) != 2 {
+			return nil, errors.New("expected a GeoJSON polygon of at least 3 [lon, lat] positions")
+		}
+	}
+	return ring, nil
+}
+
+// param returns the query string value of key, falling back
+// to the route param of the same name
+func param(c *gin.Context, key string) string {
+	if v := c.Query(key); v != "" {
+		return v
+	}
+	return c.Param(key)
+}
+
+// respond writes the result, or the error as a problem. Invalid
+// requests respond with 400, and queries matching no documents
+// with 200 and an empty list
+func (h *Dataset) respond(c *gin.Context, res []interface{}, err error) {
+	if verr, ok := err.(*dataset.ValidationError); ok {
+		p := NewProblem(c, http.StatusBadRequest, verr.Error())
+		p.InvalidParams = verr.Params
+		WriteProblem(c, p, http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		h.log.Errorw("dataset query failed", "dataset", h.ds.Name, "error", err)
+		WriteProblem(c, NewProblem(c, http.StatusInternalServerError, "the request could not be completed"), http.StatusInternalServerError)
+		return
+	}
+
+	if res == nil {
+		res = []interface{}{}
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
+	"github.com/gin-gonic/gin"
+)
+
+// Supported response formats
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatGeoJSON = "geojson"
+)
+
+// NegotiateFormat sets the `format` query param from the Accept header
+// when not provided, so that cached pages vary by format
+func NegotiateFormat(c *gin.Context) {
+	// read the url query directly, as gin caches the parsed query
+	query := c.Request.URL.Query()
+	if query.Get("format") != "" {
+		return
+	}
+
+	format := ""
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		format = FormatCSV
+	case strings.Contains(accept, "application/geo+json"):
+		format = FormatGeoJSON
+	}
+	if format == "" {
+		return
+	}
+
+	query.Set("format", format)
+	c.Request.URL.RawQuery = query.Encode()
+}
+
+// format returns the requested response format, json by default,
+// if supported by the endpoint
+func format(c *gin.Context, formats ...string) (string, error) {
+	f := strings.ToLower(c.DefaultQuery("format", FormatJSON))
+	if !dataset.IsValidKey(f, formats) {
+		return "", &dataset.ValidationError{Params: []dataset.InvalidParam{{
+			Name:   "format",
+			Reason: "unsupported format",
+			Values: []string{f},
+			Valid:  formats,
+		}}}
+	}
+	if _, err := envelope(c, f); err != nil {
+		return "", err
+	}
+	return f, nil
+}
+
+// Streamed checks if a List response is streamed, csv lists are
+// streamed unless paged
+func Streamed(c *gin.Context) bool {
+	return strings.ToLower(c.Query("format")) == FormatCSV && c.Query("limit") == ""
+}
+
+// envelope checks if the entries are requested wrapped with their
+// metadata, supported by json only. Json responses are wrapped by
+// default from v2, unless `envelope=false` is requested
+func envelope(c *gin.Context, f string) (bool, error) {
+	v := c.Query("envelope")
+	if v == "" {
+		return version(c) >= V2 && f == FormatJSON, nil
+	}
+
+	ok, err := strconv.ParseBool(v)
+	if err != nil || ok && f != FormatJSON {
+		reason := "expected true or false"
+		if err == nil {
+			reason = "envelopes are supported by json only"
+		}
+		return false, &dataset.ValidationError{Params: []dataset.InvalidParam{{
+			Name: "envelope", Reason: reason, Values: []string{v},
+		}}}
+	}
+	return ok, nil
+}
+
+// writeGeoJSON writes typed entries as a GeoJSON feature collection
+func writeGeoJSON(c *gin.Context, res []interface{}) error {
+	fc, err := dataset.NewFeatureCollection(res)
+	if err != nil {
+		return err
+	}
+	c.Header("Content-Type", "application/geo+json")
+	c.JSON(http.StatusOK, fc)
+	return nil
+}
+
+// csvWriter writes rows as text/csv, writing the header with the
+// first row so errors can still be returned before any output
+type csvWriter struct {
+	c       *gin.Context
+	w       *csv.Writer
+	columns []string
+}
+
+// newCSVWriter creates a csv writer with the columns as header
+func newCSVWriter(c *gin.Context, columns []string) *csvWriter {
+	return &csvWriter{c: c, columns: columns}
+}
+
+// Write writes the rows of a typed entry
+func (w *csvWriter) Write(entry interface{}) error {
+	if w.w == nil {
+		w.start()
+	}
+	for _, row := range dataset.Rows(entry, w.columns) {
+		if err := w.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the header if nothing was written and flushes the output
+func (w *csvWriter) Close() error {
+	if w.w == nil {
+		w.start()
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// start writes the response header and the csv header
+func (w *csvWriter) start() {
+	w.c.Header("Content-Type", "text/csv; charset=utf-8")
+	w.c.Status(http.StatusOK)
+	w.w = csv.NewWriter(w.c.Writer)
+	w.w.Write(w.columns)
+}
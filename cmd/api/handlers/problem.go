@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
+	"github.com/gin-gonic/gin"
+)
+
+// Problem represents an RFC 7807 problem details error response
+type Problem struct {
+	Type          string                 `json:"type"`
+	Title         string                 `json:"title"`
+	Status        int                    `json:"status"`
+	Detail        string                 `json:"detail,omitempty"`
+	Instance      string                 `json:"instance,omitempty"`
+	InvalidParams []dataset.InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// NewProblem creates a new problem for the status code
+func NewProblem(c *gin.Context, status int, detail string) *Problem {
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.RequestURI(),
+	}
+}
+
+// WriteProblem writes the problem as application/problem+json
+func WriteProblem(c *gin.Context, p interface{}, status int) {
+	c.Header("Content-Type", "application/problem+json")
+	c.JSON(status, p)
+}
+
+// NotFound responds with a 404 problem listing the available endpoints
+func NotFound(endpoints []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		WriteProblem(c, struct {
+			*Problem
+			AvailableEndpoints []string `json:"available_endpoints"`
+		}{
+			Problem:            NewProblem(c, http.StatusNotFound, "no endpoint matches the request path"),
+			AvailableEndpoints: endpoints,
+		}, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// API versions. The responses served under /v1 are frozen, so the
+// handlers gate any change of them on the version of the request
+const (
+	V1 = 1
+	V2 = 2
+)
+
+// versionKey is the context key of the API version
+const versionKey = "version"
+
+// Version sets the API version of the routes
+func Version(v int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(versionKey, v)
+	}
+}
+
+// version returns the API version of the request, v1 if not set
+func version(c *gin.Context) int {
+	if v := c.GetInt(versionKey); v > 0 {
+		return v
+	}
+	return V1
+}
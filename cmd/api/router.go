@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/cvcio/covid-19-api/cmd/api/handlers"
+	"github.com/cvcio/covid-19-api/models/dataset"
+	"github.com/cvcio/covid-19-api/models/global"
+	"github.com/cvcio/covid-19-api/models/gr_vaccines"
+	"github.com/cvcio/covid-19-api/models/greece"
 	"github.com/cvcio/covid-19-api/pkg/config"
-	"github.com/cvcio/covid-19-api/pkg/db"
 	"github.com/cvcio/covid-19-api/pkg/middleware"
+	"github.com/cvcio/covid-19-api/pkg/store"
 	"github.com/gin-contrib/cache"
 	"github.com/gin-contrib/cache/persistence"
 	"github.com/gin-contrib/gzip"
@@ -19,7 +24,11 @@ import (
 )
 
 // NewAPI Creates a new API Router using Gin
-func NewAPI(cfg *config.Config, dbConn *db.DB, storeLimits limiter.Store, storeCasce *persistence.RedisStore, logger *zap.Logger) http.Handler {
+//
+// Data are served from any store.Store implementation, so the API can run on
+// top of MongoDB (*db.DB) or in-memory fixtures (store.Memory), along with
+// in-memory limits and cache stores.
+func NewAPI(cfg *config.Config, dbConn store.Store, storeLimits limiter.Store, storeCasce persistence.CacheStore, logger *zap.Logger) http.Handler {
 	limiterMiddleware := mgin.NewMiddleware(limiter.New(storeLimits, limiter.Rate{
 		Period: 1 * time.Minute,
 		Limit:  300,
@@ -48,129 +57,167 @@ func NewAPI(cfg *config.Config, dbConn *db.DB, storeLimits limiter.Store, storeC
 	router.Use(limiterMiddleware)
 
 	// handlers
-	glCovid := handlers.NewGlobalHandler(cfg, dbConn, logger)
-	grCovid := handlers.NewGreeceHandler(cfg, dbConn, logger)
-	grVaccines := handlers.NewGRVaccinesHandler(cfg, dbConn, logger)
+	registry := dataset.NewRegistry(global.Dataset, greece.Dataset, gr_vaccines.Dataset)
+	datasets := make(map[string]*handlers.Dataset)
+	for _, ds := range registry.All() {
+		datasets[ds.Name] = handlers.NewDatasetHandler(cfg, dbConn, logger, ds)
+	}
+
+	// spatial indexes
+	if indexer, ok := dbConn.(store.Indexer); ok {
+		for _, ds := range registry.All() {
+			if ds.GeoField == "" {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := indexer.EnsureGeoIndex(ctx, ds.Collection, ds.GeoField); err != nil {
+				logger.Sugar().Errorf("[SERVER] Error creating %s.%s 2dsphere index: %v", ds.Collection, ds.GeoField, err)
+			}
+			cancel()
+		}
+	}
+
+	// routes, the unversioned routes serve v1 until their sunset
+	legacy := router.Group("", handlers.Version(handlers.V1), middleware.Deprecated(cfg.Legacy.Deprecation, cfg.Legacy.Sunset, "/v1"))
+	endpoints := routes(legacy, registry, datasets, storeCasce)
+	endpoints = append(endpoints, routes(router.Group("/v1", handlers.Version(handlers.V1)), registry, datasets, storeCasce)...)
+	endpoints = append(endpoints, routes(router.Group("/v2", handlers.Version(handlers.V2)), registry, datasets, storeCasce)...)
+
+	// Return all avail endpoints
+	// This is usefull when you combine multiple microservices
+	router.NoRoute(handlers.NotFound(endpoints))
+
+	return router
+}
 
-	// routes
-	glCovidRoutes := router.Group("/global")
+// routes mounts the dataset routes on the api group, returning the
+// endpoints mounted
+func routes(api *gin.RouterGroup, registry *dataset.Registry, datasets map[string]*handlers.Dataset, storeCasce persistence.CacheStore) []string {
+	var endpoints []string
+	get := func(group *gin.RouterGroup, path string, handler gin.HandlerFunc) {
+		group.GET(path, handlers.NegotiateFormat, cache.CachePage(storeCasce, 15*time.Minute, handler))
+		endpoints = append(endpoints, "GET "+group.BasePath()+path)
+	}
+	// streamed csv lists are not cached, as the cache stores the whole
+	// response again on every write
+	list := func(group *gin.RouterGroup, path string, handler gin.HandlerFunc) {
+		cached := cache.CachePage(storeCasce, 15*time.Minute, handler)
+		group.GET(path, handlers.NegotiateFormat, func(c *gin.Context) {
+			if handlers.Streamed(c) {
+				handler(c)
+				return
+			}
+			cached(c)
+		})
+		endpoints = append(endpoints, "GET "+group.BasePath()+path)
+	}
+	// posted polygon filters are not cached, as the cache is keyed by url
+	post := func(group *gin.RouterGroup, path string, handler gin.HandlerFunc) {
+		group.POST(path, handlers.NegotiateFormat, handler)
+		endpoints = append(endpoints, "POST "+group.BasePath()+path)
+	}
+
+	for _, ds := range registry.All() {
+		h, p := datasets[ds.Name], ":"+ds.Param
+		listRoutes := api.Group(ds.Path)
+		{
+			list(listRoutes, "", h.List)
+			list(listRoutes, "/"+p, h.List)
+			list(listRoutes, "/"+p+"/:keys", h.List)
+			list(listRoutes, "/"+p+"/:keys/:from", h.List)
+			list(listRoutes, "/"+p+"/:keys/:from/:to", h.List)
+			if ds.GeoField != "" {
+				post(listRoutes, "", h.List)
+			}
+		}
+	}
+
+	totalRoutes := api.Group("/agg")
 	{
-		glCovidRoutes.GET("", cache.CachePage(storeCasce, 15*time.Minute, glCovid.List))
-		glCovidRoutes.GET("/:country", cache.CachePage(storeCasce, 15*time.Minute, glCovid.List))
-		glCovidRoutes.GET("/:country/:keys", cache.CachePage(storeCasce, 15*time.Minute, glCovid.List))
-		glCovidRoutes.GET("/:country/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, glCovid.List))
-		glCovidRoutes.GET("/:country/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, glCovid.List))
+		totalRoutes.GET("", handlers.NegotiateFormat, cache.CachePage(storeCasce, 15*time.Minute, datasets[registry.Default().Name].Agg))
+		for _, ds := range registry.All() {
+			h, p := datasets[ds.Name], ":"+ds.Param
+			get(totalRoutes, ds.Path, h.Agg)
+			get(totalRoutes, ds.Path+"/"+p, h.Agg)
+			get(totalRoutes, ds.Path+"/"+p+"/:keys", h.Agg)
+			get(totalRoutes, ds.Path+"/"+p+"/:keys/:from", h.Agg)
+			get(totalRoutes, ds.Path+"/"+p+"/:keys/:from/:to", h.Agg)
+			if ds.GeoField != "" {
+				post(totalRoutes, ds.Path, h.Agg)
+			}
+		}
 	}
 
-	grCovidRoutes := router.Group("/greece")
+	sumRoutes := api.Group("/total")
 	{
-		grCovidRoutes.GET("", cache.CachePage(storeCasce, 15*time.Minute, grCovid.List))
-		grCovidRoutes.GET("/:region", cache.CachePage(storeCasce, 15*time.Minute, grCovid.List))
-		grCovidRoutes.GET("/:region/:keys", cache.CachePage(storeCasce, 15*time.Minute, grCovid.List))
-		grCovidRoutes.GET("/:region/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, grCovid.List))
-		grCovidRoutes.GET("/:region/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grCovid.List))
+		sumRoutes.GET("", handlers.NegotiateFormat, cache.CachePage(storeCasce, 15*time.Minute, datasets[registry.Default().Name].Sum))
+		for _, ds := range registry.All() {
+			h, p := datasets[ds.Name], ":"+ds.Param
+			get(sumRoutes, ds.Path, h.Sum)
+			get(sumRoutes, ds.Path+"/"+p, h.Sum)
+			get(sumRoutes, ds.Path+"/"+p+"/:from", h.Sum)
+			get(sumRoutes, ds.Path+"/"+p+"/:from/:to", h.Sum)
+			if ds.GeoField != "" {
+				post(sumRoutes, ds.Path, h.Sum)
+			}
+		}
 	}
 
-	grVaccinesRoutes := router.Group("/vaccines/greece")
+	trendRoutes := api.Group("/trends")
 	{
-		grVaccinesRoutes.GET("", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.List))
-		grVaccinesRoutes.GET("/:region", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.List))
-		grVaccinesRoutes.GET("/:region/:keys", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.List))
-		grVaccinesRoutes.GET("/:region/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.List))
-		grVaccinesRoutes.GET("/:region/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.List))
+		for _, ds := range registry.All() {
+			if len(ds.Trends) == 0 {
+				continue
+			}
+			h, p := datasets[ds.Name], ":"+ds.Param
+			get(trendRoutes, ds.Path, h.Trend)
+			get(trendRoutes, ds.Path+"/"+p, h.Trend)
+			get(trendRoutes, ds.Path+"/"+p+"/:from", h.Trend)
+			get(trendRoutes, ds.Path+"/"+p+"/:from/:to", h.Trend)
+		}
 	}
 
-	totalRoutes := router.Group("/agg")
+	rtRoutes := api.Group("/rt")
 	{
-		totalRoutes.GET("", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-		totalRoutes.GET("/global", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-		totalRoutes.GET("/global/:country", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-		totalRoutes.GET("/global/:country/:keys", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-		totalRoutes.GET("/global/:country/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-		totalRoutes.GET("/global/:country/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Agg))
-
-		totalRoutes.GET("/greece", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Agg))
-		totalRoutes.GET("/greece/:region", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Agg))
-		totalRoutes.GET("/greece/:region/:keys", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Agg))
-		totalRoutes.GET("/greece/:region/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Agg))
-		totalRoutes.GET("/greece/:region/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Agg))
-
-		totalRoutes.GET("/vaccines/greece", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Agg))
-		totalRoutes.GET("/vaccines/greece/:region", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Agg))
-		totalRoutes.GET("/vaccines/greece/:region/:keys", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Agg))
-		totalRoutes.GET("/vaccines/greece/:region/:keys/:from", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Agg))
-		totalRoutes.GET("/vaccines/greece/:region/:keys/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Agg))
+		for _, ds := range registry.All() {
+			if ds.Incidence == "" {
+				continue
+			}
+			h, p := datasets[ds.Name], ":"+ds.Param
+			get(rtRoutes, ds.Path, h.Rt)
+			get(rtRoutes, ds.Path+"/"+p, h.Rt)
+			get(rtRoutes, ds.Path+"/"+p+"/:from", h.Rt)
+			get(rtRoutes, ds.Path+"/"+p+"/:from/:to", h.Rt)
+		}
 	}
 
-	sumRoutes := router.Group("/total")
+	qualityRoutes := api.Group("/quality")
 	{
-		sumRoutes.GET("", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Sum))
-		sumRoutes.GET("/global", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Sum))
-		sumRoutes.GET("/global/:country", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Sum))
-		sumRoutes.GET("/global/:country/:from", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Sum))
-		sumRoutes.GET("/global/:country/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, glCovid.Sum))
-
-		sumRoutes.GET("/greece", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Sum))
-		sumRoutes.GET("/greece/:region", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Sum))
-		sumRoutes.GET("/greece/:region/:from", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Sum))
-		sumRoutes.GET("/greece/:region/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grCovid.Sum))
-
-		sumRoutes.GET("/vaccines/greece", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Sum))
-		sumRoutes.GET("/vaccines/greece/:region", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Sum))
-		sumRoutes.GET("/vaccines/greece/:region/:from", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Sum))
-		sumRoutes.GET("/vaccines/greece/:region/:from/:to", cache.CachePage(storeCasce, 15*time.Minute, grVaccines.Sum))
+		for _, ds := range registry.All() {
+			if len(ds.Checks) == 0 {
+				continue
+			}
+			h, p := datasets[ds.Name], ":"+ds.Param
+			get(qualityRoutes, ds.Path, h.Quality)
+			get(qualityRoutes, ds.Path+"/"+p, h.Quality)
+			get(qualityRoutes, ds.Path+"/"+p+"/:from", h.Quality)
+			get(qualityRoutes, ds.Path+"/"+p+"/:from/:to", h.Quality)
+		}
 	}
 
-	// Return all avail endpoints
-	// This is usefull when you combine multiple microservices
-	router.NoRoute(func(c *gin.Context) {
-		c.IndentedJSON(200, gin.H{
-			"available_endpoints": []string{
-				"GET /global",
-				"GET /global/:country",
-				"GET /global/:country/:keys",
-				"GET /global/:country/:keys/:from",
-				"GET /global/:country/:keys/:from/:to",
-				"GET /greece",
-				"GET /greece/:region",
-				"GET /greece/:region/:keys",
-				"GET /greece/:region/:keys/:from",
-				"GET /greece/:region/:keys/:from/:to",
-				"GET /vaccines/greece",
-				"GET /vaccines/greece/:region",
-				"GET /vaccines/greece/:region/:keys",
-				"GET /vaccines/greece/:region/:keys/:from",
-				"GET /vaccines/greece/:region/:keys/:from/:to",
-				"GET /agg/global",
-				"GET /agg/global/:country",
-				"GET /agg/global/:country/:keys",
-				"GET /agg/global/:country/:keys/:from",
-				"GET /agg/global/:country/:keys/:from/:to",
-				"GET /agg/greece",
-				"GET /agg/greece/:region",
-				"GET /agg/greece/:region/:keys",
-				"GET /agg/greece/:region/:keys/:from",
-				"GET /agg/greece/:region/:keys/:from/:to",
-				"GET /agg/vaccines/greece",
-				"GET /agg/vaccines/greece/:region",
-				"GET /agg/vaccines/greece/:region/:keys",
-				"GET /agg/vaccines/greece/:region/:keys/:from",
-				"GET /agg/vaccines/greece/:region/:keys/:from/:to",
-				"GET /total/global",
-				"GET /total/global/:country",
-				"GET /total/global/:country/:from",
-				"GET /total/global/:country/:from/:to",
-				"GET /total/greece",
-				"GET /total/greece/:region",
-				"GET /total/greece/:region/:from",
-				"GET /total/greece/:region/:from/:to",
-				"GET /total/vaccines/greece",
-				"GET /total/vaccines/greece/:region",
-				"GET /total/vaccines/greece/:region/:from",
-				"GET /total/vaccines/greece/:region/:from/:to",
-			},
-		})
-	})
+	joinRoutes := api.Group("/join")
+	{
+		for _, ds := range registry.All() {
+			h, p := datasets[ds.Name], ":"+ds.Param
+			for _, j := range ds.Joins {
+				path := ds.Path + "/" + j.Name
+				get(joinRoutes, path, h.Join(j))
+				get(joinRoutes, path+"/"+p, h.Join(j))
+				get(joinRoutes, path+"/"+p+"/:from", h.Join(j))
+				get(joinRoutes, path+"/"+p+"/:from/:to", h.Join(j))
+			}
+		}
+	}
 
-	return router
+	return endpoints
 }
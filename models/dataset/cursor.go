@@ -0,0 +1,63 @@
+package dataset
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cursor represents the position encoded in an opaque cursor
+type cursor struct {
+	Date time.Time `json:"d"`
+	ID   string    `json:"id"`
+}
+
+// encodeCursor encodes a position to an opaque cursor
+func encodeCursor(p *store.Position) string {
+	if p == nil {
+		return ""
+	}
+	b, _ := json.Marshal(cursor{Date: p.Date, ID: p.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor decodes an opaque cursor to a position
+func decodeCursor(str string) (*store.Position, error) {
+	invalid := &ValidationError{Params: []InvalidParam{{
+		Name:   "cursor",
+		Reason: "invalid cursor",
+		Values: []string{str},
+	}}}
+
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Date.IsZero() {
+		return nil, invalid
+	}
+	return &store.Position{Date: c.Date, ID: c.ID}, nil
+}
+
+// position reads the sort keys of the current document
+func (d *Dataset) position(c store.Cursor) (*store.Position, error) {
+	var doc bson.M
+	if err := c.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	p := &store.Position{}
+	switch date := doc["date"].(type) {
+	case primitive.DateTime:
+		p.Date = date.Time().UTC()
+	case time.Time:
+		p.Date = date.UTC()
+	}
+	p.ID, _ = doc[d.IDField].(string)
+	return p, nil
+}
@@ -0,0 +1,156 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestMeta represents the fields describing an area of the test dataset
+type TestMeta struct {
+	ID            *string    `bson:"id" json:"id"`
+	Sources       []string   `bson:"sources" json:"sources"`
+	Population    *int64     `bson:"population" json:"population"`
+	From          *time.Time `bson:"from,omitempty" json:"from,omitempty"`
+	To            *time.Time `bson:"to,omitempty" json:"to,omitempty"`
+	LastUpdatedAt *time.Time `bson:"last_updated_at" json:"last_updated_at"`
+}
+
+// testRecord represents a document of the test dataset
+type testRecord struct {
+	Date          *time.Time `bson:"date,omitempty" json:"date,omitempty"`
+	ID            *string    `bson:"id,omitempty" json:"id,omitempty"`
+	Population    *int64     `bson:"population,omitempty" json:"population,omitempty"`
+	Source        *string    `bson:"source,omitempty" json:"source,omitempty"`
+	LastUpdatedAt *time.Time `bson:"last_updated_at,omitempty" json:"last_updated_at,omitempty"`
+	Cases         *int64     `bson:"cases,omitempty" json:"cases,omitempty"`
+	NewCases      *int64     `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewTests      *int64     `bson:"new_tests,omitempty" json:"new_tests,omitempty"`
+	NewTestsRapid *int64     `bson:"new_tests_rapid,omitempty" json:"new_tests_rapid,omitempty"`
+}
+
+// testSeries represents the Agg entries of the test dataset
+type testSeries struct {
+	TestMeta `bson:",inline"`
+
+	Date          []*time.Time `bson:"date,omitempty" json:"date,omitempty"`
+	Cases         []*int64     `bson:"cases,omitempty" json:"cases,omitempty"`
+	NewCases      []*int64     `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewTests      []*int64     `bson:"new_tests,omitempty" json:"new_tests,omitempty"`
+	NewTestsRapid []*int64     `bson:"new_tests_rapid,omitempty" json:"new_tests_rapid,omitempty"`
+}
+
+// testTotal represents the Sum entries of the test dataset
+type testTotal struct {
+	TestMeta `bson:",inline"`
+
+	TotalCases *int64 `bson:"total_cases" json:"total_cases"`
+	Cases      *int64 `bson:"cases" json:"cases"`
+	Tests      *int64 `bson:"tests" json:"tests"`
+}
+
+// newTestDataset creates a dataset of daily cases and tests by id
+func newTestDataset() *Dataset {
+	return &Dataset{
+		Name:       "test",
+		Collection: "test",
+		Path:       "/test",
+		Param:      "id",
+		IDField:    "id",
+		GroupBy:    "id",
+		ValidKeys: []string{
+			"date", "id", "population", "source",
+			"cases", "new_cases", "new_tests", "new_tests_rapid",
+		},
+		NewRecord: func() interface{} { return new(testRecord) },
+		NewSeries: func() interface{} { return new(testSeries) },
+		NewTotal:  func() interface{} { return new(testTotal) },
+		Meta: []Field{
+			{Name: "id", Op: store.First, Key: "id"},
+			{Name: "sources", Op: store.AddToSet, Key: "source"},
+			{Name: "population", Op: store.First, Key: "population"},
+			{Name: "last_updated_at", Op: store.Last, Key: "last_updated_at"},
+		},
+		AggKeys: []string{"new_cases", "cases"},
+		SumFields: []Field{
+			{Name: "total_cases", Op: store.Last, Key: "cases"},
+			{Name: "cases", Op: store.Sum, Key: "new_cases"},
+			{Name: "tests", Op: store.Sum, Key: "new_tests"},
+		},
+		Metrics: []Metric{
+			Ratio("rapid_share", "new_tests_rapid", "new_tests", 100),
+		},
+		Trends:    []Trend{{Key: "new_cases"}, {Key: "cases", Cumulative: true}},
+		Incidence: "new_cases",
+		Checks:    []Check{{Key: "new_cases"}, {Key: "cases", Cumulative: true}},
+	}
+}
+
+// day returns the date of the n-th day from 2021-03-01
+func day(n int) time.Time {
+	return time.Date(2021, 3, 1+n, 0, 0, 0, 0, time.UTC)
+}
+
+// doc returns a document of the test dataset, with the population
+// of each id and the keys of the values
+func doc(id string, n int, values bson.M) bson.M {
+	d := bson.M{
+		"date":            day(n),
+		"id":              id,
+		"population":      int64(100000),
+		"source":          "test",
+		"last_updated_at": day(n + 1),
+	}
+	for k, v := range values {
+		d[k] = v
+	}
+	return d
+}
+
+// newTestStore loads the documents in the test collection
+func newTestStore(t *testing.T, docs ...bson.M) store.Store {
+	t.Helper()
+
+	m := store.NewMemory()
+	list := make([]interface{}, len(docs))
+	for i, d := range docs {
+		list[i] = d
+	}
+	if err := m.Load("test", list...); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// series returns the values of a key of an entry, nil for missing
+// values, single values are returned as series of one value
+func series(t *testing.T, entry interface{}, key string) []*float64 {
+	t.Helper()
+
+	v, ok := entryValues(entry)[key]
+	if !ok {
+		t.Fatalf("missing %s", key)
+	}
+	if v.Kind() == reflect.Slice {
+		out := make([]*float64, v.Len())
+		for i := range out {
+			out[i] = toFloat(v.Index(i))
+		}
+		return out
+	}
+	return []*float64{toFloat(v)}
+}
+
+// floats formats a series for comparison, missing values as nil
+func floats(list []*float64) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, v := range list {
+		if v != nil {
+			out[i] = *v
+		}
+	}
+	return out
+}
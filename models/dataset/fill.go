@@ -0,0 +1,171 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+// Gap filling methods
+const (
+	// FillNone adds the missing dates with null values
+	FillNone = "none"
+	// FillZero fills the missing values with zero
+	FillZero = "zero"
+	// FillPrevious fills the missing values with the previous value
+	FillPrevious = "previous"
+	// FillLinear interpolates the missing values between the
+	// previous and the next value
+	FillLinear = "linear"
+)
+
+// Fills lists the supported gap filling methods
+var Fills = []string{FillNone, FillZero, FillPrevious, FillLinear}
+
+// fill aligns the series of the Agg entries to every date from the
+// requested from date, or the first date of any entry, to the requested
+// to date up to today, or the last date of any entry, filling the
+// missing values of the series holding any value with the fill method
+func fill(list []interface{}, from, to time.Time, method string) {
+	if method == "" || len(list) == 0 {
+		return
+	}
+
+	// date range of the entries
+	var start, end time.Time
+	for _, entry := range list {
+		dates, ok := fieldValues(reflect.ValueOf(entry))["date"]
+		if !ok || dates.Kind() != reflect.Slice {
+			continue
+		}
+		for i := 0; i < dates.Len(); i++ {
+			t, ok := dates.Index(i).Interface().(*time.Time)
+			if !ok || t == nil {
+				continue
+			}
+			if start.IsZero() || t.Before(start) {
+				start = *t
+			}
+			if t.After(end) {
+				end = *t
+			}
+		}
+	}
+	if start.IsZero() {
+		return
+	}
+	if !from.IsZero() {
+		start = from
+	}
+	if !to.IsZero() {
+		end = to
+		if now := time.Now(); end.After(now) {
+			end = now
+		}
+	}
+	start = bucket(start, IntervalDay)
+	days := int(bucket(end, IntervalDay).Sub(start).Hours()/24) + 1
+	if days < 1 {
+		return
+	}
+
+	for _, entry := range list {
+		fillEntry(entry, start, days, method)
+	}
+}
+
+// fillEntry aligns the series of an entry to the days from start
+func fillEntry(entry interface{}, start time.Time, days int, method string) {
+	fields := fieldValues(reflect.ValueOf(entry))
+	dates, ok := fields["date"]
+	if !ok || dates.Kind() != reflect.Slice {
+		return
+	}
+
+	// day of each value
+	pos := make([]int, dates.Len())
+	for i := range pos {
+		pos[i] = -1
+		if t, ok := dates.Index(i).Interface().(*time.Time); ok && t != nil {
+			if day := int(bucket(*t, IntervalDay).Sub(start).Hours() / 24); day >= 0 && day < days {
+				pos[i] = day
+			}
+		}
+	}
+
+	for name, v := range fields {
+		if v.Kind() != reflect.Slice || name == "date" || !isNumeric(v.Type()) || v.IsNil() {
+			continue
+		}
+
+		values := make([]*float64, days)
+		for i := 0; i < v.Len() && i < len(pos); i++ {
+			if pos[i] >= 0 {
+				values[pos[i]] = toFloat(v.Index(i))
+			}
+		}
+		if !fillValues(values, method) {
+			// series without any value are left empty
+			v.Set(reflect.MakeSlice(v.Type(), days, days))
+			continue
+		}
+
+		out := reflect.MakeSlice(v.Type(), days, days)
+		for i, x := range values {
+			if x != nil {
+				out.Index(i).Set(numberOf(v.Type().Elem(), *x))
+			}
+		}
+		v.Set(out)
+	}
+
+	out := reflect.MakeSlice(dates.Type(), days, days)
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i)
+		out.Index(i).Set(reflect.ValueOf(&date))
+	}
+	dates.Set(out)
+}
+
+// fillValues fills the missing values of a series, returns false
+// if the series holds no values
+func fillValues(values []*float64, method string) bool {
+	var known []int
+	for i, v := range values {
+		if v != nil {
+			known = append(known, i)
+		}
+	}
+	if len(known) == 0 {
+		return false
+	}
+
+	switch method {
+	case FillZero:
+		for i, v := range values {
+			if v == nil {
+				zero := 0.0
+				values[i] = &zero
+			}
+		}
+	case FillPrevious:
+		for i := known[0] + 1; i < len(values); i++ {
+			if values[i] == nil {
+				prev := *values[i-1]
+				values[i] = &prev
+			}
+		}
+	case FillLinear:
+		for k := 1; k < len(known); k++ {
+			a, b := known[k-1], known[k]
+			for i := a + 1; i < b; i++ {
+				x := *values[a] + (*values[b]-*values[a])*float64(i-a)/float64(b-a)
+				if math.IsNaN(x) {
+					continue
+				}
+				values[i] = &x
+			}
+		}
+	}
+	return true
+}
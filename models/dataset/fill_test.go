@@ -0,0 +1,89 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAggMissingKeys(t *testing.T) {
+	d := newTestDataset()
+	// rapid tests are reported from the third day, and the fourth day
+	// is not reported at all
+	s := newTestStore(t,
+		doc("A", 0, bson.M{"new_tests": int64(100)}),
+		doc("A", 1, bson.M{"new_tests": int64(200)}),
+		doc("A", 2, bson.M{"new_tests": int64(400), "new_tests_rapid": int64(100)}),
+		doc("A", 4, bson.M{"new_tests": int64(500), "new_tests_rapid": int64(250)}),
+	)
+
+	tests := []struct {
+		name string
+		fill string
+		want map[string][]interface{}
+	}{
+		{
+			name: "reported dates",
+			want: map[string][]interface{}{
+				"new_tests":       {100.0, 200.0, 400.0, 500.0},
+				"new_tests_rapid": {nil, nil, 100.0, 250.0},
+			},
+		},
+		{
+			name: "fill none",
+			fill: FillNone,
+			want: map[string][]interface{}{
+				"new_tests":       {100.0, 200.0, 400.0, nil, 500.0},
+				"new_tests_rapid": {nil, nil, 100.0, nil, 250.0},
+			},
+		},
+		{
+			name: "fill previous",
+			fill: FillPrevious,
+			want: map[string][]interface{}{
+				"new_tests":       {100.0, 200.0, 400.0, 400.0, 500.0},
+				"new_tests_rapid": {nil, nil, 100.0, 100.0, 250.0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := d.Agg(s, IDs("A"), Keys("new_tests,new_tests_rapid"), From(day(0)), To(endOf(day(4))), Fill(tt.fill))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 {
+				t.Fatalf("got %d entries, want 1", len(list))
+			}
+			for key, want := range tt.want {
+				if got := floats(series(t, list[0], key)); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+			if dates := list[0].(*testSeries).Date; len(dates) != len(tt.want["new_tests"]) {
+				t.Errorf("got %d dates, want %d", len(dates), len(tt.want["new_tests"]))
+			}
+		})
+	}
+}
+
+func TestFillTo(t *testing.T) {
+	d := newTestDataset()
+	s := newTestStore(t,
+		doc("A", 0, bson.M{"new_cases": int64(1)}),
+		doc("A", 1, bson.M{"new_cases": int64(2)}),
+	)
+
+	list, err := d.Agg(s, IDs("A"), Keys("new_cases"), From(day(0)), To(endOf(day(3))), Fill(FillNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := list[0].(*testSeries)
+	if len(e.Date) != 4 || !e.Date[3].Equal(day(3)) {
+		t.Fatalf("dates = %v, want 4 days up to the requested to date", e.Date)
+	}
+	if got, want := floats(series(t, e, "new_cases")), []interface{}{1.0, 2.0, nil, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("new_cases = %v, want %v", got, want)
+	}
+}
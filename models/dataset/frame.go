@@ -0,0 +1,393 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Frame holds the numeric series of a country or region, in date order,
+// that transforms compute additional keys from. Agg entries hold a value
+// per pushed date, the records of a List are grouped by id and Sum
+// entries hold a single value
+type Frame struct {
+	// Keys lists the selected numeric keys, in request order
+	Keys []string
+	// Values holds the series by key, stored and computed
+	Values map[string][]*float64
+	// Population is the population of the country or region, if known
+	Population *float64
+	// Flags holds the computed values describing the whole frame,
+	// e.g. `population_known`
+	Flags map[string]bool
+
+	length   int
+	computed []string
+	flags    []string
+	// sources maps document keys to the keys of the frame holding
+	// their values, if they differ
+	sources map[string]string
+}
+
+// Transform computes additional keys from the values of a frame
+type Transform func(f *Frame)
+
+// Len returns the number of values of each series
+func (f *Frame) Len() int {
+	return f.length
+}
+
+// Get returns the series of a key
+func (f *Frame) Get(key string) []*float64 {
+	return f.Values[key]
+}
+
+// Source returns the series of a document key, Sum frames hold the
+// values of the document keys under the SumFields names
+func (f *Frame) Source(key string) []*float64 {
+	if name, ok := f.sources[key]; ok {
+		return f.Values[name]
+	}
+	return f.Values[key]
+}
+
+// Set sets the series of a computed key
+func (f *Frame) Set(key string, values []*float64) {
+	if _, ok := f.Values[key]; !ok {
+		f.computed = append(f.computed, key)
+	}
+	f.Values[key] = values
+}
+
+// SetFlag sets a computed value describing the whole frame
+func (f *Frame) SetFlag(key string, v bool) {
+	if f.Flags == nil {
+		f.Flags = make(map[string]bool)
+	}
+	if _, ok := f.Flags[key]; !ok {
+		f.flags = append(f.flags, key)
+	}
+	f.Flags[key] = v
+}
+
+// Computed wraps a typed entry with the keys computed by the transforms,
+// encoded after the keys of the entry
+type Computed struct {
+	Entry  interface{}
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler
+func (c *Computed) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(c.Entry)
+	if err != nil || len(c.Keys) == 0 {
+		return b, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(b, []byte("}")))
+	for i, key := range c.Keys {
+		v, err := json.Marshal(c.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 || len(b) > 2 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// flag adds the flags of the frame to a computed entry
+func (f *Frame) flag(c *Computed) {
+	for _, key := range f.flags {
+		c.Keys = append(c.Keys, key)
+		c.Values[key] = f.Flags[key]
+	}
+}
+
+// computedKeys returns the names of the keys the transforms compute
+// from the numeric keys
+func computedKeys(keys []string, opts ListOptions) []string {
+	f := newFrame(keys, 0)
+	for _, t := range opts.Transforms {
+		t(f)
+	}
+	return append(f.computed, f.flags...)
+}
+
+// computeSeries applies the transforms to each Agg entry
+func (d *Dataset) computeSeries(list []interface{}, keys []string, opts ListOptions) []interface{} {
+	if len(opts.Transforms) == 0 {
+		return list
+	}
+
+	for i, entry := range list {
+		f := frameOf(entry, keys)
+		for _, t := range opts.Transforms {
+			t(f)
+		}
+
+		c := &Computed{Entry: entry, Keys: f.computed, Values: make(map[string]interface{})}
+		for _, key := range f.computed {
+			c.Values[key] = f.Values[key]
+		}
+		f.flag(c)
+		list[i] = c
+	}
+	return list
+}
+
+// computeValues applies the transforms to the List records grouped by
+// IDField, placed by date, or to each Sum entry if byID is false
+func (d *Dataset) computeValues(list []interface{}, keys []string, opts ListOptions, byID bool) []interface{} {
+	if len(opts.Transforms) == 0 {
+		return list
+	}
+
+	// group entries, keeping their order
+	var (
+		order  []string
+		groups = make(map[string][]int)
+	)
+	for i, entry := range list {
+		id := ""
+		if byID {
+			if v, ok := fieldValues(reflect.ValueOf(entry))[d.IDField]; ok {
+				id = format(v.Interface())
+			}
+		} else {
+			id = strconv.Itoa(i)
+		}
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], i)
+	}
+
+	out := make([]interface{}, len(list))
+	for _, id := range order {
+		index := groups[id]
+
+		// build the frame of the group from the entry values, records
+		// are placed by date so that windows span consecutive days
+		slots, length := slotsOf(list, index, byID)
+		f := newFrame(keys, length)
+		if !byID {
+			f.sources = make(map[string]string)
+			for _, field := range d.SumFields {
+				f.sources[field.Key] = field.Name
+			}
+		}
+		for j, i := range index {
+			e := frameOf(list[i], keys)
+			for _, key := range keys {
+				f.Values[key][slots[j]] = e.Values[key][0]
+			}
+			if e.Population != nil {
+				f.Population = e.Population
+			}
+		}
+		for _, t := range opts.Transforms {
+			t(f)
+		}
+
+		for j, i := range index {
+			c := &Computed{Entry: list[i], Keys: f.computed, Values: make(map[string]interface{})}
+			for _, key := range f.computed {
+				var v *float64
+				if slots[j] < len(f.Values[key]) {
+					v = f.Values[key][slots[j]]
+				}
+				c.Values[key] = v
+			}
+			f.flag(c)
+			out[i] = c
+		}
+	}
+	return out
+}
+
+// slotsOf returns the position of each record of a group in its frame,
+// and the frame length. Records are placed by their day from the first
+// date of the group if byID is set and all are dated, in order otherwise
+func slotsOf(list []interface{}, index []int, byID bool) ([]int, int) {
+	slots := make([]int, len(index))
+	for j := range index {
+		slots[j] = j
+	}
+	if !byID {
+		return slots, len(index)
+	}
+
+	dates := make([]time.Time, len(index))
+	var first time.Time
+	for j, i := range index {
+		v, ok := fieldValues(reflect.ValueOf(list[i]))["date"]
+		if !ok {
+			return slots, len(index)
+		}
+		t, _ := v.Interface().(*time.Time)
+		if t == nil {
+			return slots, len(index)
+		}
+		dates[j] = bucket(*t, IntervalDay)
+		if first.IsZero() || dates[j].Before(first) {
+			first = dates[j]
+		}
+	}
+
+	length := 0
+	for j := range dates {
+		slots[j] = int(dates[j].Sub(first).Hours() / 24)
+		if slots[j]+1 > length {
+			length = slots[j] + 1
+		}
+	}
+	return slots, length
+}
+
+// numericKeys returns the numeric keys of a typed entry that transforms
+// apply to, the requested keys or all if none
+func (d *Dataset) numericKeys(entry interface{}, keys []string) []string {
+	t := reflect.TypeOf(entry)
+	if len(keys) == 0 {
+		keys = fieldNames(t)
+	}
+
+	types := fieldTypes(t)
+	var list []string
+	for _, key := range keys {
+		if key == "date" || d.isMetaKey(key) || !isNumeric(types[key]) {
+			continue
+		}
+		if !d.IsValidKey(key) && !d.isSumField(key) {
+			continue
+		}
+		list = appendKeys(list, key)
+	}
+	return list
+}
+
+// isSumField checks if a key is one of the dataset SumFields
+func (d *Dataset) isSumField(key string) bool {
+	for _, f := range d.SumFields {
+		if f.Name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// newFrame creates a frame of empty series
+func newFrame(keys []string, length int) *Frame {
+	f := &Frame{
+		Keys:   keys,
+		Values: make(map[string][]*float64),
+		length: length,
+	}
+	for _, key := range keys {
+		f.Values[key] = make([]*float64, length)
+	}
+	return f
+}
+
+// frameOf builds the frame of a typed entry, single values are read
+// as series of one value
+func frameOf(entry interface{}, keys []string) *Frame {
+	fields := fieldValues(reflect.ValueOf(entry))
+
+	length := 1
+	if date, ok := fields["date"]; ok && date.Kind() == reflect.Slice {
+		length = date.Len()
+	}
+
+	f := newFrame(keys, length)
+	for _, key := range keys {
+		v, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len() && i < length; i++ {
+				f.Values[key][i] = toFloat(v.Index(i))
+			}
+			continue
+		}
+		f.Values[key][0] = toFloat(v)
+	}
+	if v, ok := fields["population"]; ok {
+		f.Population = toFloat(v)
+	}
+	return f
+}
+
+// fieldTypes returns the field types of a struct type by json key,
+// including the fields of embedded structs
+func fieldTypes(t reflect.Type) map[string]reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	types := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for name, ft := range fieldTypes(f.Type) {
+				types[name] = ft
+			}
+			continue
+		}
+		if name := jsonName(f); name != "" {
+			types[name] = f.Type
+		}
+	}
+	return types
+}
+
+// isNumeric checks if a field type holds numbers, or series of numbers
+func isNumeric(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a numeric field value to *float64
+func toFloat(v reflect.Value) *float64 {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var f float64
+	switch v.Kind() {
+	case reflect.Int64:
+		f = float64(v.Int())
+	case reflect.Float64:
+		f = v.Float()
+	default:
+		return nil
+	}
+	return &f
+}
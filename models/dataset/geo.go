@@ -0,0 +1,104 @@
+package dataset
+
+import (
+	"fmt"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// geo builds the store spatial filter from list options
+func (d *Dataset) geo(opts ListOptions) *store.Geo {
+	switch {
+	case len(opts.Near) == 2:
+		return &store.Geo{Field: d.GeoField, Center: opts.Near, Radius: opts.Radius}
+	case len(opts.BBox) == 4:
+		minLon, minLat, maxLon, maxLat := opts.BBox[0], opts.BBox[1], opts.BBox[2], opts.BBox[3]
+		return &store.Geo{Field: d.GeoField, Polygon: [][]float64{
+			{minLon, minLat}, {maxLon, minLat}, {maxLon, maxLat}, {minLon, maxLat}, {minLon, minLat},
+		}}
+	case isPolygon(opts.Polygon):
+		ring := opts.Polygon
+		// close the ring if needed
+		if first, last := ring[0], ring[len(ring)-1]; first[0] != last[0] || first[1] != last[1] {
+			ring = append(append([][]float64{}, ring...), first)
+		}
+		return &store.Geo{Field: d.GeoField, Polygon: ring}
+	}
+	return nil
+}
+
+// setDistance sets the distance of a located entry from the Near position
+func setDistance(entry interface{}, opts ListOptions) {
+	if len(opts.Near) != 2 {
+		return
+	}
+	if l, ok := entry.(Located); ok {
+		if loc := l.Location(); loc != nil && len(loc.Coordinates) == 2 {
+			l.SetDistance(store.Distance(opts.Near, loc.Coordinates))
+		}
+	}
+}
+
+// validateGeo checks the spatial list options
+func (d *Dataset) validateGeo(opts ListOptions) []InvalidParam {
+	var params []InvalidParam
+	var names []string
+	if opts.Near != nil {
+		names = append(names, "near")
+	}
+	if opts.BBox != nil {
+		names = append(names, "bbox")
+	}
+	if opts.Polygon != nil {
+		names = append(names, "polygon")
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if d.GeoField == "" {
+		return []InvalidParam{{Name: names[0], Reason: fmt.Sprintf("spatial filters are not supported by %s", d.Name)}}
+	}
+	if len(names) > 1 {
+		return []InvalidParam{{Name: names[0], Reason: "only one of near, bbox and polygon can be set", Values: names}}
+	}
+
+	switch names[0] {
+	case "near":
+		if len(opts.Near) != 2 || !isPosition(opts.Near) {
+			params = append(params, InvalidParam{Name: "near", Reason: "expected lon,lat"})
+		}
+		if opts.Radius <= 0 {
+			params = append(params, InvalidParam{Name: "radius", Reason: "expected a positive radius in kilometers"})
+		}
+	case "bbox":
+		if len(opts.BBox) != 4 || !isPosition(opts.BBox[:2]) || !isPosition(opts.BBox[2:]) ||
+			opts.BBox[0] >= opts.BBox[2] || opts.BBox[1] >= opts.BBox[3] {
+			params = append(params, InvalidParam{Name: "bbox", Reason: "expected min lon,min lat,max lon,max lat"})
+		}
+	case "polygon":
+		if !isPolygon(opts.Polygon) {
+			params = append(params, InvalidParam{Name: "polygon", Reason: "expected a GeoJSON polygon of at least 3 [lon, lat] positions"})
+		}
+	}
+	return params
+}
+
+// isPolygon checks if a ring holds at least 3 [lon, lat] positions
+// within range
+func isPolygon(ring [][]float64) bool {
+	if len(ring) < 3 {
+		return false
+	}
+	for _, p := range ring {
+		if !isPosition(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPosition checks if a [lon, lat] position is within range
+func isPosition(p []float64) bool {
+	return len(p) == 2 && p[0] >= -180 && p[0] <= 180 && p[1] >= -90 && p[1] <= 90
+}
@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// FeatureCollection represents a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// Feature represents a GeoJSON feature, with the entry `loc` as
+// geometry and the remaining keys as properties
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Point                 `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// NewFeatureCollection converts typed entries to a GeoJSON feature
+// collection, entries without `loc` get a null geometry
+func NewFeatureCollection(entries []interface{}) (*FeatureCollection, error) {
+	fc := &FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]*Feature, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		f, err := NewFeature(entry)
+		if err != nil {
+			return nil, err
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return fc, nil
+}
+
+// NewFeature converts a typed entry to a GeoJSON feature
+func NewFeature(entry interface{}) (*Feature, error) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Loc *Point `json:"loc"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	// keep numbers as they are encoded
+	properties := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&properties); err != nil {
+		return nil, err
+	}
+	delete(properties, "loc")
+
+	return &Feature{
+		Type:       "Feature",
+		Geometry:   doc.Loc,
+		Properties: properties,
+	}, nil
+}
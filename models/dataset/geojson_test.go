@@ -0,0 +1,35 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewFeatureCollection(t *testing.T) {
+	type located struct {
+		ID    string `json:"id"`
+		Cases *int64 `json:"cases"`
+		Loc   *Point `json:"loc,omitempty"`
+	}
+	cases := int64(12345678901)
+	entries := []interface{}{
+		&located{ID: "A", Cases: &cases, Loc: &Point{Type: "Point", Coordinates: []float64{21.8, 39.1}}},
+		&located{ID: "B"},
+	}
+
+	fc, err := NewFeatureCollection(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[21.8,39.1]},"properties":{"cases":12345678901,"id":"A"}},` +
+		`{"type":"Feature","geometry":null,"properties":{"cases":null,"id":"B"}}]}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,244 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// Trend describes a series trend metrics are computed for
+type Trend struct {
+	// Key is the document key of the series
+	Key string
+	// Cumulative is set for running totals, e.g. `cases`, and unset
+	// for daily values, e.g. `new_cases`
+	Cumulative bool
+}
+
+// Growth returns a transform computing the trend metrics of the trend
+// series selected in the frame, the daily growth rate as
+// `<key>_growth_rate` and the change of the last 7 days over the previous
+// 7 days as `<key>_wow_change` in %, and the doubling or halving time over
+// the window as `<key>_doubling_time` and `<key>_halving_time` in days.
+// Doubling and halving times compare the running total of cumulative
+// series, or the sum of the last window days of daily series, with its
+// value a window earlier. Values that can't be computed, e.g. from a zero
+// base or missing values, are null
+func Growth(trends []Trend, window int) Transform {
+	return func(f *Frame) {
+		for _, t := range trends {
+			values, ok := f.Values[t.Key]
+			if !ok || !IsValidKey(t.Key, f.Keys) {
+				continue
+			}
+
+			// daily values and the level the doubling time is computed on
+			daily, level := values, windowSums(values, window)
+			if t.Cumulative {
+				daily, level = diff(values), values
+			}
+
+			weekly := windowSums(daily, 7)
+			doubling := make([]*float64, f.Len())
+			halving := make([]*float64, f.Len())
+			for i := range values {
+				r := ratio(level, i, window)
+				if r == nil || *r <= 0 || *r == 1 {
+					continue
+				}
+				td := float64(window) * math.Ln2 / math.Log(*r)
+				if td > 0 {
+					doubling[i] = &td
+				} else {
+					td = -td
+					halving[i] = &td
+				}
+			}
+
+			keys := growthKeys(t.Key)
+			f.Set(keys[0], change(values, 1))
+			f.Set(keys[1], change(weekly, 7))
+			f.Set(keys[2], doubling)
+			f.Set(keys[3], halving)
+		}
+	}
+}
+
+// Trend lists the trend metrics of each group at its latest date, computed
+// over the trend series of the window days before. If no from date is
+// set, the series start as many days before the latest date as needed
+func (d *Dataset) Trend(s store.Store, window int, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if opts.From.IsZero() {
+		to, err := d.endDate(s, opts)
+		if err != nil {
+			return nil, err
+		}
+		optionsList = append(optionsList, From(to.AddDate(0, 0, -trendDays(window))), To(endOf(to)), Date(""))
+	}
+
+	// lags are counted in days, so the series are aligned to
+	// consecutive days
+	if opts.Fill == "" {
+		optionsList = append(optionsList, Fill(FillNone))
+	}
+	optionsList = append(optionsList, Keys(d.trendKeys()), Transforms(Growth(d.Trends, window)))
+	list, err := d.Agg(s, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range list {
+		c, ok := entry.(*Computed)
+		if !ok {
+			continue
+		}
+		list[i] = d.latest(c)
+	}
+	return list, nil
+}
+
+// TrendColumns returns the flat columns of the Trend entries, the Meta
+// fields followed by the date and the trend metrics
+func (d *Dataset) TrendColumns() []string {
+	list := d.metaKeys()
+	list = appendKeys(list, "from", "to")
+	list = columns(metaOf(d.NewSeries()), list)
+	list = append(list, "date")
+	for _, t := range d.Trends {
+		list = append(list, t.Key)
+		list = append(list, growthKeys(t.Key)...)
+	}
+	return list
+}
+
+// latest reduces a computed Agg entry to the Meta of the group and the
+// values of its latest date
+func (d *Dataset) latest(c *Computed) *Computed {
+	fields := fieldValues(reflect.ValueOf(c.Entry))
+	out := &Computed{Entry: metaOf(c.Entry), Values: make(map[string]interface{})}
+
+	date, ok := fields["date"]
+	if !ok || date.Len() == 0 {
+		return out
+	}
+	i := date.Len() - 1
+
+	out.Keys = []string{"date"}
+	out.Values["date"] = date.Index(i).Interface()
+	for _, t := range d.Trends {
+		var v interface{}
+		if series, ok := fields[t.Key]; ok && i < series.Len() {
+			v = series.Index(i).Interface()
+		}
+		out.Keys = append(out.Keys, t.Key)
+		out.Values[t.Key] = v
+
+		for _, key := range growthKeys(t.Key) {
+			var v *float64
+			if values, ok := c.Values[key].([]*float64); ok && i < len(values) {
+				v = values[i]
+			}
+			out.Keys = append(out.Keys, key)
+			out.Values[key] = v
+		}
+	}
+	return out
+}
+
+// growthKeys returns the keys of the trend metrics of a series
+func growthKeys(key string) []string {
+	return []string{key + "_growth_rate", key + "_wow_change", key + "_doubling_time", key + "_halving_time"}
+}
+
+// trendKeys returns the keys of the trend series
+func (d *Dataset) trendKeys() string {
+	var keys []string
+	for _, t := range d.Trends {
+		keys = append(keys, t.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+// trendDays returns the days of history the trend metrics of the latest
+// date are computed from, with a week to spare for late reports
+func trendDays(window int) int {
+	if window < 7 {
+		window = 7
+	}
+	return 2*window + 7
+}
+
+// metaOf returns the embedded Meta of an Agg or Sum entry, or the entry
+// if none
+func metaOf(entry interface{}) interface{} {
+	v := reflect.ValueOf(entry)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return entry
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Anonymous {
+			return v.Field(i).Addr().Interface()
+		}
+	}
+	return entry
+}
+
+// windowSums returns the sums of the window values ending on each value,
+// null if any value of the window is missing
+func windowSums(values []*float64, window int) []*float64 {
+	out := make([]*float64, len(values))
+	for i := window - 1; i < len(values); i++ {
+		var sum float64
+		complete := true
+		for _, v := range values[i-window+1 : i+1] {
+			if v == nil {
+				complete = false
+				break
+			}
+			sum += *v
+		}
+		if complete {
+			s := sum
+			out[i] = &s
+		}
+	}
+	return out
+}
+
+// diff returns the daily differences of a cumulative series
+func diff(values []*float64) []*float64 {
+	out := make([]*float64, len(values))
+	for i := 1; i < len(values); i++ {
+		if values[i] == nil || values[i-1] == nil {
+			continue
+		}
+		d := *values[i] - *values[i-1]
+		out[i] = &d
+	}
+	return out
+}
+
+// ratio returns the ratio of a value over the value lag days before
+func ratio(values []*float64, i, lag int) *float64 {
+	if i < lag || values[i] == nil || values[i-lag] == nil || *values[i-lag] == 0 {
+		return nil
+	}
+	r := *values[i] / *values[i-lag]
+	return &r
+}
+
+// change returns the % change of each value over the value lag days before
+func change(values []*float64, lag int) []*float64 {
+	out := make([]*float64, len(values))
+	for i := range values {
+		if r := ratio(values, i, lag); r != nil {
+			c := (*r - 1) * 100
+			out[i] = &c
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTrend(t *testing.T) {
+	d := newTestDataset()
+	// A doubles every day, B halves every day
+	var docs []bson.M
+	for i := 0; i < 15; i++ {
+		docs = append(docs,
+			doc("A", i, bson.M{"new_cases": int64(1) << uint(i), "cases": int64(1)<<uint(i+1) - 1}),
+			doc("B", i, bson.M{"new_cases": int64(1) << uint(14-i)}),
+		)
+	}
+	s := newTestStore(t, docs...)
+
+	list, err := d.Trend(s, 7, From(day(0)), To(endOf(day(14))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d entries, want 2", len(list))
+	}
+
+	tests := []struct {
+		entry int
+		key   string
+		want  *float64
+	}{
+		{0, "new_cases", float(16384)},
+		{0, "new_cases_growth_rate", float(100)},
+		{0, "new_cases_wow_change", float(12700)},
+		{0, "new_cases_doubling_time", float(1)},
+		{0, "new_cases_halving_time", nil},
+		{0, "cases_growth_rate", float(100.0061)},
+		{0, "cases_doubling_time", float(0.9992)},
+		{1, "new_cases_growth_rate", float(-50)},
+		{1, "new_cases_wow_change", float(-99.2188)},
+		{1, "new_cases_doubling_time", nil},
+		{1, "new_cases_halving_time", float(1)},
+		// B has no cumulative series
+		{1, "cases_growth_rate", nil},
+	}
+	for _, tt := range tests {
+		got := series(t, list[tt.entry], tt.key)[0]
+		switch {
+		case tt.want == nil && got != nil:
+			t.Errorf("%d %s = %v, want null", tt.entry, tt.key, *got)
+		case tt.want != nil && (got == nil || !approx(*got, *tt.want, 1e-4)):
+			t.Errorf("%d %s = %v, want %v", tt.entry, tt.key, floats([]*float64{got}), *tt.want)
+		}
+	}
+
+	c := list[0].(*Computed)
+	if date := c.Values["date"].(*time.Time); !date.Equal(day(14)) {
+		t.Errorf("date = %v, want the latest date", date)
+	}
+}
+
+// float returns a pointer to the value
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestListGrowthGaps(t *testing.T) {
+	d := newTestDataset()
+	// the fourth day is not reported
+	var docs []bson.M
+	for _, i := range []int{0, 1, 2, 4, 5} {
+		docs = append(docs, doc("A", i, bson.M{"new_cases": int64(1) << uint(i), "cases": int64(1)<<uint(i+1) - 1}))
+	}
+	s := newTestStore(t, docs...)
+
+	list, err := d.List(s, Keys("new_cases,cases"), From(day(0)), To(endOf(day(5))), Transforms(Growth(d.Trends, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 5 {
+		t.Fatalf("got %d records, want 5", len(list))
+	}
+
+	// lags are counted in days, not records
+	var rates []interface{}
+	for _, entry := range list {
+		rates = append(rates, floats(series(t, entry, "new_cases_growth_rate"))...)
+	}
+	if want := []interface{}{nil, 100.0, 100.0, nil, 100.0}; !reflect.DeepEqual(rates, want) {
+		t.Errorf("new_cases_growth_rate = %v, want %v", rates, want)
+	}
+	if got := series(t, list[3], "cases_doubling_time")[0]; got == nil || !approx(*got, 2*math.Ln2/math.Log(31.0/7), 1e-9) {
+		t.Errorf("cases_doubling_time = %v, want the doubling time of the 2 days before", floats([]*float64{got}))
+	}
+	if got := series(t, list[4], "cases_doubling_time")[0]; got != nil {
+		t.Errorf("cases_doubling_time = %v, want null after the missing day", *got)
+	}
+}
@@ -0,0 +1,260 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// joinDays is the number of days joined by default
+const joinDays = 56
+
+// Join describes the join of the Agg series of another dataset to the
+// areas of a dataset, e.g. the vaccinations of the greek regions to
+// their cases, served under `/join/<dataset path>/<join name>`
+type Join struct {
+	// Name is the name of the join, unique per dataset
+	Name string
+	// Dataset is the joined dataset
+	Dataset *Dataset
+	// Match is the Meta key matching the areas of the datasets,
+	// e.g. `region`
+	Match string
+	// Areas maps the Match values of the joined dataset to the Match
+	// values of the dataset, where they differ. Joined areas mapped to
+	// the same area are merged
+	Areas map[string]string
+	// Keys lists the keys of the dataset in the joined series
+	Keys []string
+	// JoinKeys lists the keys of the joined dataset in the joined
+	// series, metrics are computed after merging the joined areas
+	JoinKeys []string
+	// Correlate lists the pairs of keys correlated over the date range
+	Correlate []Pair
+}
+
+// Pair represents a pair of joined keys
+type Pair struct {
+	X string
+	Y string
+}
+
+// Correlation represents the Pearson correlation of two joined series,
+// over the N dates both are known
+type Correlation struct {
+	X string   `json:"x"`
+	Y string   `json:"y"`
+	R *float64 `json:"r"`
+	N int      `json:"n"`
+}
+
+// Join returns the series of each country or region joined with the
+// series of the matching areas of the joined dataset, aligned by date,
+// over the last 8 weeks by default
+func (d *Dataset) Join(s store.Store, j *Join, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if opts.From.IsZero() {
+		to, err := d.endDate(s, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.From, opts.To, opts.Date = to.AddDate(0, 0, -joinDays), endOf(to), ""
+	}
+
+	optionsList = append(optionsList, From(opts.From), To(opts.To), Date(opts.Date), Keys(strings.Join(j.Keys, ",")))
+	list, err := d.Agg(s, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, ids, err := j.areas(s, opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range list {
+		fields := entryValues(entry)
+		area := ""
+		if v, ok := fields[j.Match]; ok {
+			area = strings.ToLower(format(v.Interface()))
+		}
+		other, ok := joined[area]
+
+		c := &Computed{Entry: metaOf(entryOf(entry)), Keys: j.columns(), Values: make(map[string]interface{})}
+		c.Values[j.idKey()] = append([]string{}, ids[area]...)
+
+		// align the series to the dates of both entries
+		series := []map[string]reflect.Value{fields}
+		if ok {
+			series = append(series, entryValues(other))
+		}
+		dates, pos := mergeDates(series)
+		c.Values["date"] = datePointers(dates)
+		for n, keys := range [][]string{j.Keys, j.JoinKeys} {
+			for _, key := range keys {
+				values := make([]*float64, len(dates))
+				if n < len(series) {
+					if v, ok := series[n][key]; ok && v.Kind() == reflect.Slice {
+						for k := 0; k < v.Len() && k < len(pos[n]); k++ {
+							if pos[n][k] >= 0 {
+								values[pos[n][k]] = toFloat(v.Index(k))
+							}
+						}
+					}
+				}
+				c.Values[key] = values
+			}
+		}
+
+		var correlations []Correlation
+		for _, p := range j.Correlate {
+			x, _ := c.Values[p.X].([]*float64)
+			y, _ := c.Values[p.Y].([]*float64)
+			r, n := pearson(x, y)
+			correlations = append(correlations, Correlation{X: p.X, Y: p.Y, R: r, N: n})
+		}
+		c.Values["correlation"] = correlations
+		list[i] = c
+	}
+	return list, nil
+}
+
+// JoinColumns returns the flat columns of the Join entries, the Meta
+// fields of the dataset followed by the joined series
+func (d *Dataset) JoinColumns(j *Join) []string {
+	list := d.metaKeys()
+	list = appendKeys(list, "from", "to")
+	list = columns(metaOf(d.NewSeries()), list)
+	keys := j.columns()
+	return append(list, keys[:len(keys)-1]...)
+}
+
+// columns returns the keys of the Join entries
+func (j *Join) columns() []string {
+	keys := []string{j.idKey(), "date"}
+	keys = append(keys, j.Keys...)
+	keys = append(keys, j.JoinKeys...)
+	return append(keys, "correlation")
+}
+
+// idKey returns the key of the IDField values of the joined areas,
+// e.g. `vaccines_ids`
+func (j *Join) idKey() string {
+	return j.Name + "_ids"
+}
+
+// areas returns the Agg entries of the joined dataset by the lower case
+// Match value of the dataset, along with the IDField values merged in
+// each entry
+func (j *Join) areas(s store.Store, from, to time.Time) (map[string]interface{}, map[string][]string, error) {
+	d := j.Dataset
+
+	// metrics are computed over the merged areas
+	keyOpts := ListOptions{Keys: strings.Join(j.JoinKeys, ",")}
+	keys := d.keys(keyOpts)
+	list, err := d.Agg(s, From(from), To(to), Keys(strings.Join(keys, ",")))
+	if err != nil {
+		return nil, nil, err
+	}
+	// carry the last values over the dates an area is not reported,
+	// so that merged areas are not undercounted
+	fill(list, from, to, FillPrevious)
+
+	var (
+		names  []string
+		groups = make(map[string][]interface{})
+		ids    = make(map[string][]string)
+	)
+	for _, entry := range list {
+		fields := fieldValues(reflect.ValueOf(entry))
+		area := ""
+		if v, ok := fields[j.Match]; ok {
+			area = format(v.Interface())
+		}
+		if name, ok := j.Areas[area]; ok {
+			area = name
+		}
+		area = strings.ToLower(area)
+
+		if _, ok := groups[area]; !ok {
+			names = append(names, area)
+		}
+		groups[area] = append(groups[area], entry)
+		if v, ok := fields[d.IDField]; ok {
+			ids[area] = append(ids[area], format(v.Interface()))
+		}
+	}
+
+	merged := make([]interface{}, 0, len(names))
+	for _, area := range names {
+		entry := d.NewSeries()
+		d.merge(entry, groups[area], []string{j.Match})
+		merged = append(merged, entry)
+	}
+	metrics := []Transform{metricTransform(d.metrics(keyOpts))}
+	merged = d.computeSeries(merged, d.numericKeys(d.NewSeries(), keys), ListOptions{Transforms: metrics})
+
+	out := make(map[string]interface{})
+	for i, area := range names {
+		sort.Strings(ids[area])
+		out[area] = merged[i]
+	}
+	return out, ids, nil
+}
+
+// entryOf returns the typed entry of a computed entry
+func entryOf(entry interface{}) interface{} {
+	if c, ok := entry.(*Computed); ok {
+		return c.Entry
+	}
+	return entry
+}
+
+// datePointers returns the dates as a date series
+func datePointers(dates []time.Time) []*time.Time {
+	out := make([]*time.Time, len(dates))
+	for i := range dates {
+		out[i] = &dates[i]
+	}
+	return out
+}
+
+// pearson returns the Pearson correlation of two series over the values
+// known in both, nil if less than 3 or either series is constant
+func pearson(x, y []*float64) (*float64, int) {
+	var xs, ys []float64
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != nil && y[i] != nil {
+			xs = append(xs, *x[i])
+			ys = append(ys, *y[i])
+		}
+	}
+	n := len(xs)
+	if n < 3 {
+		return nil, n
+	}
+
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(n)
+	my /= float64(n)
+
+	var sxy, sxx, syy float64
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		syy += (ys[i] - my) * (ys[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return nil, n
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	return &r, n
+}
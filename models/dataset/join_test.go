@@ -0,0 +1,39 @@
+package dataset
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		x    []*float64
+		y    []*float64
+		want *float64
+		n    int
+	}{
+		{"positive", values(1, 2, 3, 4), values(2, 4, 6, 8), float(1), 4},
+		{"negative", values(1, 2, 3, 4), values(8, 6, 4, 2), float(-1), 4},
+		{"partial", values(1, 2, 3, 4), values(1, 3, 2, 4), float(0.8), 4},
+		// only the dates both values are known are correlated
+		{"missing values", values(1, nan, 2, 3, 9), values(1, 5, 2, 3, nan), float(1), 3},
+		{"too few values", values(1, 2, nan), values(1, 2, 3), nil, 2},
+		{"constant", values(1, 2, 3), values(5, 5, 5), nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, n := pearson(tt.x, tt.y)
+			if n != tt.n {
+				t.Errorf("n = %d, want %d", n, tt.n)
+			}
+			switch {
+			case tt.want == nil && r != nil:
+				t.Errorf("r = %v, want null", *r)
+			case tt.want != nil && (r == nil || !approx(*r, *tt.want, 1e-9)):
+				t.Errorf("r = %v, want %v", floats([]*float64{r}), *tt.want)
+			}
+		})
+	}
+}
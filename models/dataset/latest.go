@@ -0,0 +1,198 @@
+package dataset
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// DateLatest selects the most recent date present in the collection
+const DateLatest = "latest"
+
+// latestCacheTTL is the time the latest dates are cached for
+const latestCacheTTL = 5 * time.Minute
+
+// latestCache holds the most recent date of each IDField value, read
+// from the store
+type latestCache struct {
+	mu        sync.Mutex
+	store     store.Store
+	dates     map[string]time.Time
+	updatedAt time.Time
+}
+
+// Latest returns the most recent date present in the collection, or
+// of the IDField values if any, zero if there are no documents
+func (d *Dataset) Latest(s store.Store, ids ...string) (time.Time, error) {
+	dates, err := d.latestDates(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for id, t := range dates {
+		if len(ids) > 0 && !IsValidKey(id, ids) {
+			continue
+		}
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+// Resolve returns the date selected by the Date option, or the latest
+// date of the requested countries / regions if no date range is set and
+// the dataset has no DefaultFrom, zero if a date range is requested
+func (d *Dataset) Resolve(s store.Store, optionsList ...func(*ListOptions)) (time.Time, error) {
+	return d.resolve(s, d.parseOpts(optionsList))
+}
+
+// resolve returns the date selected by the options
+func (d *Dataset) resolve(s store.Store, opts ListOptions) (time.Time, error) {
+	sel := strings.ToLower(strings.TrimSpace(opts.Date))
+	switch {
+	case sel == "" && (d.hasRange(opts) || !d.DefaultFrom.IsZero()):
+		return time.Time{}, nil
+	case sel == "":
+		sel = DateLatest
+	case d.hasRange(opts):
+		return time.Time{}, &ValidationError{Params: []InvalidParam{{
+			Name: "date", Reason: "can't be combined with a from / to date range", Values: []string{opts.Date},
+		}}}
+	}
+
+	return d.selectDate(s, opts, sel)
+}
+
+// selectDate returns the date of a selector, relative to the latest
+// date of the requested countries / regions
+func (d *Dataset) selectDate(s store.Store, opts ListOptions, sel string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", sel); err == nil {
+		return t, nil
+	}
+
+	days, ok := latestOffset(sel)
+	if !ok {
+		return time.Time{}, &ValidationError{Params: []InvalidParam{{
+			Name: "date", Reason: "expected latest, latest-N or YYYY-MM-DD", Values: []string{sel},
+		}}}
+	}
+
+	latest, err := d.Latest(s, d.query(opts).IDs...)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if latest.IsZero() {
+		latest = time.Now()
+	}
+	return bucket(latest, IntervalDay).AddDate(0, 0, -days), nil
+}
+
+// hasRange checks if the options request a date range, other than the
+// default range of the dataset
+func (d *Dataset) hasRange(opts ListOptions) bool {
+	if !d.DefaultFrom.IsZero() {
+		return !opts.From.Equal(d.DefaultFrom)
+	}
+	return !opts.From.IsZero() || !opts.To.IsZero()
+}
+
+// withDate limits the options to the date selected, if any
+func (d *Dataset) withDate(s store.Store, opts ListOptions) (ListOptions, error) {
+	day, err := d.resolve(s, opts)
+	if err != nil || day.IsZero() {
+		return opts, err
+	}
+	opts.From, opts.To, opts.Date = day, endOf(day), ""
+	return opts, nil
+}
+
+// endDate returns the date the windows of the Trend, Rt, Quality and
+// Join entries end on, the To date or the date selected, the latest
+// date by default
+func (d *Dataset) endDate(s store.Store, opts ListOptions) (time.Time, error) {
+	if opts.Date == "" && !opts.To.IsZero() {
+		return opts.To, nil
+	}
+	sel := strings.ToLower(strings.TrimSpace(opts.Date))
+	if sel == "" {
+		sel = DateLatest
+	}
+	return d.selectDate(s, opts, sel)
+}
+
+// endOf returns the last moment of a day
+func endOf(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1).Add(-time.Millisecond)
+}
+
+// latestOffset parses the days of `latest` and `latest-N` selectors
+func latestOffset(sel string) (int, bool) {
+	if sel == DateLatest {
+		return 0, true
+	}
+	if !strings.HasPrefix(sel, DateLatest+"-") {
+		return 0, false
+	}
+	days, err := strconv.Atoi(strings.TrimPrefix(sel, DateLatest+"-"))
+	if err != nil || days < 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+// latestDates returns the most recent date of each IDField value,
+// cached for 5 minutes. The store is queried without holding the lock,
+// requests missing the cache at once may query it concurrently
+func (d *Dataset) latestDates(s store.Store) (map[string]time.Time, error) {
+	d.dates.mu.Lock()
+	if d.dates.dates != nil && d.dates.store == s && time.Since(d.dates.updatedAt) < latestCacheTTL {
+		dates := d.dates.dates
+		d.dates.mu.Unlock()
+		return dates, nil
+	}
+	d.dates.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := s.Aggregate(ctx, d.Collection, store.Query{}, store.Group{
+		By: d.IDField,
+		Fields: []Field{
+			{Name: "id", Op: store.First, Key: d.IDField},
+			{Name: "date", Op: store.Max, Key: "date"},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "db.%s.latest()", d.Collection)
+	}
+	defer c.Close(ctx)
+
+	dates := make(map[string]time.Time)
+	for c.Next(ctx) {
+		var entry struct {
+			ID   string    `bson:"id"`
+			Date time.Time `bson:"date"`
+		}
+		if err := c.Decode(&entry); err != nil {
+			return nil, errors.Wrapf(err, "db.%s.latest()", d.Collection)
+		}
+		if entry.ID != "" && !entry.Date.IsZero() {
+			dates[strings.ToUpper(entry.ID)] = entry.Date
+		}
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrapf(err, "db.%s.latest()", d.Collection)
+	}
+
+	d.dates.mu.Lock()
+	d.dates.store, d.dates.dates, d.dates.updatedAt = s, dates, time.Now()
+	d.dates.mu.Unlock()
+	return dates, nil
+}
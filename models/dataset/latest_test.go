@@ -0,0 +1,48 @@
+package dataset
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLatest(t *testing.T) {
+	d := newTestDataset()
+	// documents in no particular date order, B is a day behind
+	s := newTestStore(t,
+		doc("A", 2, bson.M{"new_cases": int64(3)}),
+		doc("B", 1, bson.M{"new_cases": int64(2)}),
+		doc("A", 0, bson.M{"new_cases": int64(1)}),
+		doc("B", 0, bson.M{"new_cases": int64(1)}),
+		doc("A", 1, bson.M{"new_cases": int64(2)}),
+	)
+
+	tests := []struct {
+		name string
+		ids  []string
+		want int
+	}{
+		{"all", nil, 2},
+		{"A", []string{"A"}, 2},
+		{"B", []string{"B"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, err := d.Latest(s, tt.ids...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !latest.Equal(day(tt.want)) {
+				t.Errorf("latest = %v, want %v", latest, day(tt.want))
+			}
+		})
+	}
+
+	date, err := d.Resolve(s, IDs("B"), Date("latest-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !date.Equal(day(0)) {
+		t.Errorf("latest-1 of B = %v, want %v", date, day(0))
+	}
+}
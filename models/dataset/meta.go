@@ -0,0 +1,295 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// Units of the numeric keys
+const (
+	UnitCount   = "count"
+	UnitPercent = "percent"
+	UnitRatio   = "ratio"
+	UnitDays    = "days"
+	UnitKm      = "km"
+)
+
+// Meta describes the data served in a response envelope
+type Meta struct {
+	// From and To are the first and last dates served, the dates of
+	// the documents summed if the entries have no dates
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+	// Count is the number of entries served
+	Count int `json:"count"`
+	// Sources lists the sources of the entries served
+	Sources []string `json:"sources"`
+	// LastUpdatedAt is the most recent ingestion time of the entries
+	// served
+	LastUpdatedAt *time.Time `json:"last_updated_at"`
+	// Units maps the numeric keys served to their units
+	Units map[string]string `json:"units"`
+	// Transforms lists the transforms applied to the entries
+	Transforms []string `json:"transforms"`
+}
+
+// Envelope wraps the entries of a response with their Meta
+type Envelope struct {
+	Meta *Meta         `json:"meta"`
+	Data []interface{} `json:"data"`
+}
+
+// NewMeta describes the typed entries served, with the units of the
+// numeric columns
+func (d *Dataset) NewMeta(s store.Store, res []interface{}, columns []string, optionsList ...func(*ListOptions)) (*Meta, error) {
+	opts, err := d.withDate(s, d.parseOpts(optionsList))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Meta{
+		Count:      len(res),
+		Sources:    []string{},
+		Units:      d.ColumnUnits(columns),
+		Transforms: []string{},
+	}
+
+	var ids []string
+	for _, entry := range res {
+		fields := entryValues(entry)
+		ids = appendKeys(ids, stringValues(fields[d.IDField])...)
+		for _, key := range []string{"date", "from", "to"} {
+			for _, t := range timeValues(fields[key]) {
+				if m.From == nil || t.Before(*m.From) {
+					from := t
+					m.From = &from
+				}
+				if m.To == nil || t.After(*m.To) {
+					to := t
+					m.To = &to
+				}
+			}
+		}
+		for _, t := range timeValues(fields["last_updated_at"]) {
+			if m.LastUpdatedAt == nil || t.After(*m.LastUpdatedAt) {
+				updated := t
+				m.LastUpdatedAt = &updated
+			}
+		}
+		for _, key := range []string{"source", "sources"} {
+			for _, source := range stringValues(fields[key]) {
+				m.Sources = appendKeys(m.Sources, source)
+			}
+		}
+	}
+	sort.Strings(m.Sources)
+
+	// entries without their dates, sources or ingestion times, e.g.
+	// totals or lists of the requested keys, are described by the
+	// documents served
+	if len(res) > 0 && (m.From == nil || len(m.Sources) == 0 || m.LastUpdatedAt == nil) {
+		if err := d.queryMeta(s, m, opts, ids); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// queryMeta sets the missing dates, sources and most recent ingestion
+// time of the meta from the documents matching the options, limited to
+// the ids and dates served if any
+func (d *Dataset) queryMeta(s store.Store, m *Meta, opts ListOptions, served []string) error {
+	var fields []Field
+	if m.From == nil {
+		// the first date of the documents sorted by date
+		fields = append(fields,
+			Field{Name: "from", Op: store.First, Key: "date"},
+			Field{Name: "to", Op: store.Max, Key: "date"},
+		)
+	}
+	for _, f := range d.Meta {
+		switch f.Name {
+		case "sources":
+			fields = append(fields, Field{Name: f.Name, Op: store.AddToSet, Key: f.Key})
+		case "last_updated_at":
+			fields = append(fields, Field{Name: f.Name, Op: store.Max, Key: f.Key})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	q := d.query(opts)
+	if m.From != nil && m.To != nil {
+		q.From, q.To, q.Sort = *m.From, endOf(*m.To), nil
+	}
+	if len(served) > 0 {
+		q.IDs = ids(served)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := s.Aggregate(ctx, d.Collection, q, store.Group{Fields: fields})
+	if err != nil {
+		return errors.Wrapf(err, "db.%s.meta()", d.Collection)
+	}
+	defer c.Close(ctx)
+
+	for c.Next(ctx) {
+		var entry struct {
+			From          *time.Time `bson:"from"`
+			To            *time.Time `bson:"to"`
+			Sources       []string   `bson:"sources"`
+			LastUpdatedAt *time.Time `bson:"last_updated_at"`
+		}
+		if err := c.Decode(&entry); err != nil {
+			return errors.Wrapf(err, "db.%s.meta()", d.Collection)
+		}
+		if m.From == nil && m.To == nil {
+			m.From, m.To = entry.From, entry.To
+		}
+		if len(m.Sources) == 0 {
+			m.Sources = appendKeys(m.Sources, entry.Sources...)
+			sort.Strings(m.Sources)
+		}
+		if m.LastUpdatedAt == nil {
+			m.LastUpdatedAt = entry.LastUpdatedAt
+		}
+	}
+	return errors.Wrapf(c.Err(), "db.%s.meta()", d.Collection)
+}
+
+// ColumnUnits returns the units of the numeric keys of the columns,
+// keys of unknown units are left out
+func (d *Dataset) ColumnUnits(columns []string) map[string]string {
+	units := make(map[string]string)
+	for _, key := range columns {
+		if unit := d.unit(key); unit != "" {
+			units[key] = unit
+		}
+	}
+	return units
+}
+
+// unit returns the unit of a stored or computed key, empty if unknown
+func (d *Dataset) unit(key string) string {
+	if unit, ok := d.Units[key]; ok {
+		return unit
+	}
+	if m, ok := d.metric(key); ok {
+		return m.Unit
+	}
+
+	switch key {
+	case "date", "from", "to", d.IDField, d.GroupBy:
+		return ""
+	case "distance":
+		return UnitKm
+	case "rt", "rt_lower", "rt_upper":
+		return UnitRatio
+	case "issues":
+		return UnitCount
+	}
+	if IsValidKey(key, Flags) {
+		return UnitCount
+	}
+
+	for _, per := range PerUnits {
+		if strings.HasSuffix(key, "_per_"+per) {
+			return "per " + per
+		}
+	}
+	for _, suffix := range []string{"_growth_rate", "_wow_change"} {
+		if strings.HasSuffix(key, suffix) {
+			return UnitPercent
+		}
+	}
+	for _, suffix := range []string{"_doubling_time", "_halving_time"} {
+		if strings.HasSuffix(key, suffix) {
+			return UnitDays
+		}
+	}
+	if i := strings.Index(key, "_rolling_"); i > 0 {
+		return d.unit(key[:i])
+	}
+
+	// integer keys hold counts
+	for _, entry := range []interface{}{d.NewRecord(), d.NewSeries(), d.NewTotal()} {
+		if t, ok := fieldTypes(reflect.TypeOf(entry))[key]; ok && isInteger(t) {
+			return UnitCount
+		}
+	}
+	// joined keys
+	for _, j := range d.Joins {
+		if unit := j.Dataset.unit(key); unit != "" {
+			return unit
+		}
+	}
+	return ""
+}
+
+// isInteger checks if a field type holds integers, or series of integers
+func isInteger(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Int64
+}
+
+// timeValues returns the dates of a date or date series field
+func timeValues(v reflect.Value) []time.Time {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Slice {
+		var list []time.Time
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, timeValues(v.Index(i))...)
+		}
+		return list
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok && !t.IsZero() {
+		return []time.Time{t}
+	}
+	return nil
+}
+
+// stringValues returns the values of a string or string list field
+func stringValues(v reflect.Value) []string {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Slice {
+		var list []string
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, stringValues(v.Index(i))...)
+		}
+		return list
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String && v.String() != "" {
+		return []string{v.String()}
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package dataset
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// List Endpoint
+func (d *Dataset) List(s store.Store, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	page, err := d.Page(s, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+	return page.Data, nil
+}
+
+// Page lists a page of documents, sorted by date and IDField. Pages
+// are limited with the Limit option and continued with the Offset or
+// the Cursor option, keys requested in paged or transformed lists always
+// include the date and IDField keys the records are ordered by
+func (d *Dataset) Page(s store.Store, optionsList ...func(*ListOptions)) (*Page, error) {
+	page := &Page{}
+	next, err := d.Each(s, func(entry interface{}) error {
+		page.Data = append(page.Data, entry)
+		return nil
+	}, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Next = next
+	return page, nil
+}
+
+// Each streams the documents of a page to fn as they are decoded,
+// without buffering them, and returns the cursor of the next page
+func (d *Dataset) Each(s store.Store, fn func(entry interface{}) error, optionsList ...func(*ListOptions)) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	opts := d.parseOpts(optionsList)
+	if err := d.validate(s, opts); err != nil {
+		return "", err
+	}
+	opts, err := d.withDate(s, opts)
+	if err != nil {
+		return "", err
+	}
+
+	q := d.query(opts)
+	q.Keys = d.keys(opts)
+	if opts.Offset > 0 {
+		q.Skip = opts.Offset
+	}
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return "", err
+		}
+		q.After = after
+	}
+	if (len(opts.Near) > 0 || opts.Geometry) && len(q.Keys) > 0 {
+		// distances and features are computed from the location
+		q.Keys = appendKeys(q.Keys, d.GeoField)
+	}
+	if opts.Per != "" && len(q.Keys) > 0 {
+		// values are normalised by the population of the records
+		q.Keys = appendKeys(q.Keys, "population")
+	}
+	if (opts.Limit > 0 || len(opts.Transforms) > 0) && len(q.Keys) > 0 {
+		// cursors and transforms follow the date and id of the records
+		q.Keys = appendKeys(q.Keys, "date", d.IDField)
+	}
+	if opts.Limit > 0 {
+		// fetch an extra document to know if there is a next page
+		q.Limit = opts.Limit + 1
+	}
+
+	c, err := s.Find(ctx, d.Collection, q)
+	if err != nil {
+		return "", errors.Wrapf(err, "db.%s.find()", d.Collection)
+	}
+	defer c.Close(ctx)
+
+	// transforms are computed over the records of each id,
+	// so transformed records are buffered
+	var buffer []interface{}
+	emit := fn
+	if len(opts.Transforms) > 0 {
+		emit = func(entry interface{}) error {
+			buffer = append(buffer, entry)
+			return nil
+		}
+	}
+
+	// decode entries, keeping the position of the last document
+	var (
+		count int
+		last  *store.Position
+		next  string
+	)
+	for c.Next(ctx) {
+		if opts.Limit > 0 && count == opts.Limit {
+			next = encodeCursor(last)
+			break
+		}
+		entry := d.NewRecord()
+		if err := c.Decode(entry); err != nil {
+			return "", errors.Wrapf(err, "db.%s.find()", d.Collection)
+		}
+		if opts.Limit > 0 {
+			if last, err = d.position(c); err != nil {
+				return "", errors.Wrapf(err, "db.%s.find()", d.Collection)
+			}
+		}
+		setDistance(entry, opts)
+		if err := emit(entry); err != nil {
+			return "", err
+		}
+		count++
+	}
+	if err := c.Err(); err != nil {
+		return "", errors.Wrapf(err, "db.%s.find()", d.Collection)
+	}
+
+	if len(buffer) > 0 {
+		keys := d.numericKeys(d.NewRecord(), d.keys(opts))
+		for _, entry := range d.computeValues(buffer, keys, opts, true) {
+			if err := fn(entry); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// Agg Aggregate Data
+func (d *Dataset) Agg(s store.Store, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if err := d.validate(s, opts); err != nil {
+		return nil, err
+	}
+	opts, err := d.withDate(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// set group fields
+	fields := append([]Field{}, d.Meta...)
+	fields = append(fields, Field{Name: "from", Op: store.First, Key: "date"}, Field{Name: "to", Op: store.Last, Key: "date"})
+	keys := d.keys(opts)
+	if len(keys) == 0 {
+		keys = d.AggKeys
+	}
+	// dates are always pushed to match the series values
+	keys = appendKeys([]string{"date"}, keys...)
+	for _, key := range keys {
+		// meta keys describe the group and are not pushed
+		if d.isMetaKey(key) {
+			continue
+		}
+		fields = append(fields, Field{Name: key, Op: store.Push, Key: key})
+	}
+
+	list, err := d.aggregate(s, d.query(opts), fields, d.NewSeries)
+	if err != nil {
+		return nil, errors.Wrapf(err, "db.%s.agg()", d.Collection)
+	}
+	list = d.groupEntries(list, opts.GroupBy, d.NewSeries)
+	// issues are checked on the reported daily values
+	var issues [][]Issue
+	if opts.Quality {
+		if issues, err = d.checkQuality(s, list, opts.To); err != nil {
+			return nil, err
+		}
+	}
+	fill(list, opts.From, opts.To, opts.Fill)
+	for _, entry := range list {
+		d.resample(entry, opts.Interval)
+		setDistance(entry, opts)
+	}
+
+	list = d.computeSeries(list, d.numericKeys(d.NewSeries(), keys), opts)
+	if opts.Quality {
+		list = withQuality(list, issues)
+	}
+	return list, nil
+}
+
+// Sum Data
+func (d *Dataset) Sum(s store.Store, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if err := d.validate(s, opts); err != nil {
+		return nil, err
+	}
+	opts, err := d.withDate(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// set group fields
+	fields := append([]Field{}, d.Meta...)
+	fields = append(fields, d.SumFields...)
+
+	var list []interface{}
+	if opts.Interval == "" || opts.Interval == IntervalDay {
+		list, err = d.aggregate(s, d.query(opts), fields, d.NewTotal)
+		if err != nil {
+			return nil, errors.Wrapf(err, "db.%s.sum()", d.Collection)
+		}
+		list = d.groupEntries(list, opts.GroupBy, d.NewTotal)
+	} else {
+		// buckets are told apart by their date range
+		if !d.isSumField("from") {
+			fields = append(fields, Field{Name: "from", Op: store.First, Key: "date"}, Field{Name: "to", Op: store.Last, Key: "date"})
+		}
+		if list, err = d.sumIntervals(s, d.query(opts), fields, opts.Interval, opts.GroupBy); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range list {
+		setDistance(entry, opts)
+	}
+
+	return d.computeValues(list, d.sumKeys(), opts, false), nil
+}
+
+// sumKeys returns the numeric keys of the Sum entries
+func (d *Dataset) sumKeys() []string {
+	var keys []string
+	for _, f := range d.SumFields {
+		keys = append(keys, f.Name)
+	}
+	return d.numericKeys(d.NewTotal(), keys)
+}
+
+// parseOpts parses list options over the dataset defaults, the
+// requested metrics are computed after the requested transforms
+func (d *Dataset) parseOpts(optionsList []func(*ListOptions)) ListOptions {
+	opts := d.DefaultOpts()
+	for _, o := range optionsList {
+		o(&opts)
+	}
+	if metrics := d.metrics(opts); len(metrics) > 0 {
+		opts.Transforms = append(opts.Transforms, metricTransform(metrics))
+	}
+	return opts
+}
+
+// query builds the store query from list options
+func (d *Dataset) query(opts ListOptions) store.Query {
+	q := store.Query{
+		Field: d.IDField,
+		From:  opts.From,
+		To:    opts.To,
+		Sort:  []string{"date", d.IDField},
+	}
+
+	// set id filters if exist in query param
+	q.IDs = ids(opts.IDs)
+	q.Exclude = ids(opts.Exclude)
+
+	// limit ids to the region
+	if region, ok := d.region(opts.Region); ok {
+		if len(q.IDs) == 0 {
+			q.IDs = region
+		} else {
+			var list []string
+			for _, id := range q.IDs {
+				if IsValidKey(id, region) {
+					list = append(list, id)
+				}
+			}
+			// the region name never matches an id, so requesting ids
+			// outside of the region matches nothing
+			if len(list) == 0 {
+				list = []string{opts.Region}
+			}
+			q.IDs = list
+		}
+	}
+
+	// set spatial filter
+	q.Geo = d.geo(opts)
+
+	// set default date limit (today), if the date is not resolved
+	if opts.From.IsZero() && opts.To.IsZero() {
+		year, month, day := time.Now().Date()
+		q.From = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	return q
+}
+
+// appendKeys appends the keys missing from the list
+func appendKeys(list []string, keys ...string) []string {
+	for _, key := range keys {
+		if !IsValidKey(key, list) {
+			list = append(list, key)
+		}
+	}
+	return list
+}
+
+// ids normalizes a list of ids, dropping empty and duplicate values
+func ids(list []string) []string {
+	var ids []string
+	for _, id := range list {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if id != "" && !IsValidKey(id, ids) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// keys returns the valid requested keys, followed by the keys the
+// requested metrics are computed from, or nil if all keys are requested
+func (d *Dataset) keys(opts ListOptions) []string {
+	if strings.Contains(opts.Keys, "all") || opts.Keys == "" {
+		return nil
+	}
+
+	var keys []string
+	for _, key := range strings.Split(opts.Keys, ",") {
+		key = strings.TrimSpace(key)
+		if d.IsValidKey(key) {
+			keys = appendKeys(keys, key)
+		}
+	}
+	for _, m := range d.metrics(opts) {
+		keys = appendKeys(keys, m.Keys...)
+	}
+	return keys
+}
+
+// aggregate groups the documents matching the query by the dataset
+// GroupBy key, sorted by the dataset IDField
+func (d *Dataset) aggregate(s store.Store, q store.Query, fields []Field, newEntry func() interface{}) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := s.Aggregate(ctx, d.Collection, q, store.Group{
+		By:     d.GroupBy,
+		Fields: fields,
+		Sort:   d.IDField,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// decode to list
+	return decode(ctx, c, newEntry)
+}
+
+// isMetaKey checks if a key is one of the dataset Meta fields
+func (d *Dataset) isMetaKey(key string) bool {
+	for _, f := range d.Meta {
+		if f.Name == key || f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// decode reads all documents from the cursor into the typed
+// entries returned by newEntry
+func decode(ctx context.Context, c store.Cursor, newEntry func() interface{}) ([]interface{}, error) {
+	defer c.Close(ctx)
+
+	var list []interface{}
+	for c.Next(ctx) {
+		entry := newEntry()
+		if err := c.Decode(entry); err != nil {
+			return nil, err
+		}
+		list = append(list, entry)
+	}
+	return list, c.Err()
+}
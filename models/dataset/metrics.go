@@ -0,0 +1,171 @@
+package dataset
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PopulationKnown is the key flagging if the population the metrics per
+// population are computed over is known, as they are null otherwise
+const PopulationKnown = "population_known"
+
+// Metric describes a key computed from the stored keys of a dataset,
+// requested along with the stored keys
+type Metric struct {
+	// Name is the key of the metric
+	Name string
+	// Keys lists the document keys the metric is computed from,
+	// fetched along with the metric
+	Keys []string
+	// Unit is the unit of the metric values, e.g. `percent`
+	Unit string
+	// Compute computes the metric series from a frame of the keys
+	Compute func(f *Frame) []*float64
+}
+
+// Ratio returns a metric of the ratio of two keys times the scale,
+// null where the denominator is missing or zero
+func Ratio(name, numerator, denominator string, scale float64) Metric {
+	return Metric{
+		Name: name,
+		Keys: []string{numerator, denominator},
+		Unit: ratioUnit(scale),
+		Compute: func(f *Frame) []*float64 {
+			return divide(f.Source(numerator), f.Source(denominator), f.Len(), scale)
+		},
+	}
+}
+
+// RollingRatio returns a metric of the ratio of the sums of two keys
+// over a window of consecutive values times the scale, null where any
+// denominator of the window is missing or the sum is zero
+func RollingRatio(name, numerator, denominator string, scale float64, window int) Metric {
+	return Metric{
+		Name: name,
+		Keys: []string{numerator, denominator},
+		Unit: ratioUnit(scale),
+		Compute: func(f *Frame) []*float64 {
+			num := rolling(f.Source(numerator), RollingSum, window, AlignTrailing, PartialNull)
+			den := windowSums(f.Source(denominator), window)
+			return divide(num, den, f.Len(), scale)
+		},
+	}
+}
+
+// Rate returns a metric of a key per population times the scale,
+// null where the population is missing or zero
+func Rate(name, key string, scale float64) Metric {
+	return Metric{
+		Name: name,
+		Keys: []string{key, "population"},
+		Unit: rateUnit(scale),
+		Compute: func(f *Frame) []*float64 {
+			out := make([]*float64, f.Len())
+			if f.Population == nil || *f.Population <= 0 {
+				return out
+			}
+			for i, v := range f.Source(key) {
+				if v != nil && i < len(out) {
+					n := *v / *f.Population * scale
+					out[i] = &n
+				}
+			}
+			return out
+		},
+	}
+}
+
+// RollingRate returns a metric of the sum of a key over a window of
+// consecutive values per population times the scale, null where the
+// population is missing or zero
+func RollingRate(name, key string, scale float64, window int) Metric {
+	return Metric{
+		Name: name,
+		Keys: []string{key, "population"},
+		Unit: rateUnit(scale),
+		Compute: func(f *Frame) []*float64 {
+			out := make([]*float64, f.Len())
+			if f.Population == nil || *f.Population <= 0 {
+				return out
+			}
+			for i, v := range rolling(f.Source(key), RollingSum, window, AlignTrailing, PartialNull) {
+				if v != nil && i < len(out) {
+					n := *v / *f.Population * scale
+					out[i] = &n
+				}
+			}
+			return out
+		},
+	}
+}
+
+// metric returns a dataset metric by name
+func (d *Dataset) metric(name string) (Metric, bool) {
+	for _, m := range d.Metrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
+
+// metrics returns the requested metrics, in request order
+func (d *Dataset) metrics(opts ListOptions) []Metric {
+	var list []Metric
+	for _, key := range strings.Split(opts.Keys, ",") {
+		if m, ok := d.metric(strings.TrimSpace(key)); ok {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// metricTransform returns a transform computing the metrics
+func metricTransform(metrics []Metric) Transform {
+	return func(f *Frame) {
+		for _, m := range metrics {
+			f.Set(m.Name, m.Compute(f))
+			if IsValidKey("population", m.Keys) {
+				f.SetFlag(PopulationKnown, f.Population != nil && *f.Population > 0)
+			}
+		}
+	}
+}
+
+// divide divides two series times the scale
+func divide(num, den []*float64, length int, scale float64) []*float64 {
+	out := make([]*float64, length)
+	for i := range out {
+		if i >= len(num) || i >= len(den) || num[i] == nil || den[i] == nil || *den[i] == 0 {
+			continue
+		}
+		v := *num[i] / *den[i] * scale
+		out[i] = &v
+	}
+	return out
+}
+
+// ratioUnit returns the unit of a ratio times the scale
+func ratioUnit(scale float64) string {
+	if scale == 100 {
+		return UnitPercent
+	}
+	return UnitRatio
+}
+
+// rateUnit returns the unit of a value per population times the scale
+func rateUnit(scale float64) string {
+	switch scale {
+	case 1:
+		return "per " + PerCapita
+	case 100:
+		return UnitPercent
+	case 1000:
+		return "per 1k"
+	case 100000:
+		return "per " + Per100k
+	case 1000000:
+		return "per " + Per1m
+	}
+	return "per " + strconv.FormatFloat(scale, 'f', -1, 64)
+}
@@ -0,0 +1,318 @@
+package dataset
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// Field represents a single accumulator of an aggregation group stage,
+// e.g. `total_cases: { $last: "$cases" }`
+type Field = store.Field
+
+// Dataset describes a collection served by the API. Every registered
+// dataset gets the List, Agg and Sum endpoints mounted automatically
+type Dataset struct {
+	// Name is the unique name of the dataset
+	Name string
+	// Collection is the store collection to query
+	Collection string
+	// Path is the route the dataset is served under, e.g. `/global`
+	Path string
+	// Param is the name of the route parameter filtering by IDField,
+	// e.g. `country` or `region`
+	Param string
+	// IDField is the document key matched against the Param value,
+	// e.g. `iso3` or `uid`
+	IDField string
+	// GroupBy is the document key used to group documents in Agg and Sum
+	GroupBy string
+	// GeoField is the GeoJSON point key filtered by the spatial options,
+	// e.g. `loc`, empty if spatial filters are not supported
+	GeoField string
+	// ValidKeys lists the document keys that can be requested
+	ValidKeys []string
+	// NewRecord, NewSeries and NewTotal return a pointer to the typed
+	// structs decoded in List, Agg and Sum accordingly
+	NewRecord func() interface{}
+	NewSeries func() interface{}
+	NewTotal  func() interface{}
+	// DefaultFrom sets the start date used when none is provided,
+	// if zero only the current date is returned
+	DefaultFrom time.Time
+	// Meta lists the fields that describe each group in Agg and Sum
+	Meta []Field
+	// AggKeys lists the keys pushed in Agg when no keys are requested
+	AggKeys []string
+	// SumFields lists the fields computed in Sum
+	SumFields []Field
+	// Trends lists the series trend metrics are computed for, empty
+	// if trends are not supported
+	Trends []Trend
+	// Rollups lists the groupings of the Agg and Sum entries into
+	// larger areas, requested with the GroupBy option
+	Rollups []Rollup
+	// Regions maps the names of the regions the dataset can be
+	// filtered by to their IDField values, e.g. `EU`
+	Regions map[string][]string
+	// Weights maps the ratio keys to the keys weighting them when
+	// merged in rollups, e.g. incidence by population
+	Weights map[string]string
+	// Metrics lists the keys computed from the stored keys, returned
+	// only when requested
+	Metrics []Metric
+	// Units maps the stored keys that are not counts to their units,
+	// e.g. `percent`
+	Units map[string]string
+	// Incidence is the daily new cases key Rt is estimated from,
+	// empty if Rt is not supported
+	Incidence string
+	// Checks lists the keys checked for data-quality issues, empty
+	// if quality reports are not supported
+	Checks []Check
+	// Joins lists the datasets joined to the areas of the dataset
+	Joins []*Join
+
+	// ids caches the known IDField values, used to validate requests
+	ids idCache
+	// dates caches the most recent date of each IDField value
+	dates latestCache
+}
+
+// idCache holds the known IDField values of a dataset, read from
+// the store
+type idCache struct {
+	mu        sync.Mutex
+	store     store.Store
+	ids       []string
+	updatedAt time.Time
+}
+
+// Point represents a GeoJSON point, as stored in the `loc` key
+type Point struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
+}
+
+// Page represents a page of List results
+type Page struct {
+	Data []interface{}
+	// Next is the cursor of the next page, empty on the last page
+	Next string
+}
+
+// ListOptions represents the filter structure to query
+// the database
+type ListOptions struct {
+	Limit    int
+	Offset   int
+	Cursor   string
+	IDs      []string
+	Exclude  []string
+	Keys     string
+	From     time.Time
+	To       time.Time
+	Near     []float64
+	Radius   float64
+	BBox     []float64
+	Polygon  [][]float64
+	Per      string
+	Interval string
+	GroupBy  string
+	Region   string
+	Fill     string
+	Date     string
+	Quality  bool
+	Geometry bool
+
+	Transforms []Transform
+}
+
+// NewListOpts create a new ListOptions struct
+func NewListOpts() []func(*ListOptions) {
+	return make([]func(*ListOptions), 0)
+}
+
+// Limit sets the limit
+func Limit(i int) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Limit = i
+	}
+}
+
+// Offset sets the number of documents to skip
+func Offset(i int) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Offset = i
+	}
+}
+
+// Cursor sets the opaque cursor to continue listing after,
+// as returned in Page.Next
+func Cursor(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Cursor = i
+	}
+}
+
+// IDs sets the ids (iso3 country codes, nuts codes) to filter by
+func IDs(i ...string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.IDs = i
+	}
+}
+
+// Exclude sets the ids (iso3 country codes, nuts codes) to leave out
+func Exclude(i ...string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Exclude = i
+	}
+}
+
+// Keys sets the keys to return
+func Keys(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Keys = i
+	}
+}
+
+// From sets the start date to retrieve data from
+func From(i time.Time) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.From = i
+	}
+}
+
+// To sets the end date to retrieve data from
+func To(i time.Time) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.To = i
+	}
+}
+
+// Near sets the [lon, lat] position and the radius in kilometers
+// to retrieve data within
+func Near(lon, lat, radius float64) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Near = []float64{lon, lat}
+		l.Radius = radius
+	}
+}
+
+// BBox sets the bounding box to retrieve data within
+func BBox(minLon, minLat, maxLon, maxLat float64) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.BBox = []float64{minLon, minLat, maxLon, maxLat}
+	}
+}
+
+// Polygon sets the polygon ring of [lon, lat] positions to retrieve
+// data within
+func Polygon(ring [][]float64) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Polygon = ring
+	}
+}
+
+// Per adds the values of the numeric keys normalised by population,
+// per 100k, per 1m or per capita, see PerPopulation
+func Per(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Per = i
+		l.Transforms = append(l.Transforms, PerPopulation(i))
+	}
+}
+
+// Interval sets the interval Agg series and Sum entries are resampled to
+func Interval(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Interval = i
+	}
+}
+
+// GroupBy sets the rollup the Agg and Sum entries are grouped by
+func GroupBy(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.GroupBy = i
+	}
+}
+
+// Region sets the region to retrieve data for, see Dataset.Regions
+func Region(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Region = i
+	}
+}
+
+// Date selects a single date, `latest`, `latest-N` days or YYYY-MM-DD,
+// instead of a date range
+func Date(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Date = i
+	}
+}
+
+// Fill sets the method the missing dates of the Agg series are filled with
+func Fill(i string) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Fill = i
+	}
+}
+
+// Quality annotates the Agg entries with the data-quality issues
+// of their series
+func Quality(i bool) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Quality = i
+	}
+}
+
+// Geometry always returns the GeoField of the List records, e.g. for
+// GeoJSON features
+func Geometry(i bool) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Geometry = i
+	}
+}
+
+// Transforms adds transforms computing additional keys, applied
+// in order
+func Transforms(t ...Transform) func(*ListOptions) {
+	return func(l *ListOptions) {
+		l.Transforms = append(l.Transforms, t...)
+	}
+}
+
+// Located is implemented by entries with a location, reporting their
+// distance from the Near position
+type Located interface {
+	Location() *Point
+	SetDistance(km float64)
+}
+
+// DefaultOpts sets the defaults
+func (d *Dataset) DefaultOpts() ListOptions {
+	l := ListOptions{}
+	l.Limit = -1
+	if !d.DefaultFrom.IsZero() {
+		l.From = d.DefaultFrom
+		l.To = time.Now()
+	}
+	return l
+}
+
+// IsValidKey checks if a key can be requested from the dataset
+func (d *Dataset) IsValidKey(str string) bool {
+	return IsValidKey(str, d.ValidKeys)
+}
+
+// IsValidKey checks if a string is in an array
+func IsValidKey(str string, list []string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
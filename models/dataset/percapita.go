@@ -0,0 +1,51 @@
+package dataset
+
+// Per capita units
+const (
+	Per100k   = "100k"
+	Per1m     = "1m"
+	PerCapita = "capita"
+)
+
+// PerUnits lists the supported per capita units
+var PerUnits = []string{Per100k, Per1m, PerCapita}
+
+// perScale returns the population scale of a per capita unit
+func perScale(per string) float64 {
+	switch per {
+	case Per100k:
+		return 100000
+	case Per1m:
+		return 1000000
+	}
+	return 1
+}
+
+// PerPopulation returns a transform computing the values of the numeric
+// keys, and of the keys computed by the previous transforms, normalised
+// by the population of the frame as `<key>_per_<per>`. Values of frames
+// without a population, or with a zero population as the pseudo-regions
+// of imported cases, are null
+func PerPopulation(per string) Transform {
+	scale := perScale(per)
+	return func(f *Frame) {
+		keys := append(append([]string{}, f.Keys...), f.computed...)
+		for _, key := range keys {
+			if key == "population" {
+				continue
+			}
+
+			out := make([]*float64, f.Len())
+			if f.Population != nil && *f.Population > 0 {
+				for i, v := range f.Get(key) {
+					if v == nil {
+						continue
+					}
+					n := *v / *f.Population * scale
+					out[i] = &n
+				}
+			}
+			f.Set(key+"_per_"+per, out)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPerPopulation(t *testing.T) {
+	d := newTestDataset()
+	s := newTestStore(t,
+		doc("A", 0, bson.M{"population": int64(50000), "new_cases": int64(10)}),
+		doc("A", 1, bson.M{"population": int64(50000), "new_cases": int64(20)}),
+		// pseudo-regions without a population
+		doc("B", 0, bson.M{"population": int64(0), "new_cases": int64(5)}),
+		doc("B", 1, bson.M{"population": int64(0), "new_cases": int64(5)}),
+	)
+	opts := []func(*ListOptions){Keys("new_cases"), From(day(0)), To(endOf(day(1)))}
+
+	t.Run("agg", func(t *testing.T) {
+		list, err := d.Agg(s, append(opts, Per(Per100k))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("got %d entries, want 2", len(list))
+		}
+		if got, want := floats(series(t, list[0], "new_cases_per_100k")), []interface{}{20.0, 40.0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("A = %v, want %v", got, want)
+		}
+		if got, want := floats(series(t, list[1], "new_cases_per_100k")), []interface{}{nil, nil}; !reflect.DeepEqual(got, want) {
+			t.Errorf("B = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("sum", func(t *testing.T) {
+		list, err := d.Sum(s, append(opts, IDs("A"), Per(Per1m))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := floats(series(t, list[0], "cases_per_1m")), []interface{}{600.0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("cases_per_1m = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rolling", func(t *testing.T) {
+		list, err := d.Agg(s, append(opts, IDs("A"), Transforms(Rolling(RollingSum, 2, AlignTrailing, PartialNull)), Per(PerCapita))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := floats(series(t, list[0], "new_cases_rolling_sum_2_per_capita")), []interface{}{nil, 0.0006}; !reflect.DeepEqual(got, want) {
+			t.Errorf("new_cases_rolling_sum_2_per_capita = %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,276 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// Data-quality flags
+const (
+	// FlagNegative flags negative daily values, e.g. after corrections
+	FlagNegative = "negative_value"
+	// FlagDecreasing flags cumulative values lower than the previous one
+	FlagDecreasing = "decreasing_cumulative"
+	// FlagOutlier flags daily values far above the median of the
+	// previous days, e.g. backlog dumps
+	FlagOutlier = "outlier"
+	// FlagStale flags entries updated long before the latest entry or
+	// the latest date of the dataset
+	FlagStale = "stale"
+)
+
+// Flags lists the data-quality flags
+var Flags = []string{FlagNegative, FlagDecreasing, FlagOutlier, FlagStale}
+
+const (
+	// outlierDays is the number of previous days the median of a
+	// daily value is computed over, outlierMin the minimum number of
+	// values needed
+	outlierDays = 14
+	outlierMin  = 7
+	// outlierFactor is the ratio to the median a daily value has to
+	// exceed, and outlierDiff the minimum difference, so that small
+	// counts are not flagged
+	outlierFactor = 5
+	outlierDiff   = 10
+	// staleDays is the number of days an entry can be updated before
+	// the latest entry
+	staleDays = 3
+	// qualityDays is the number of days checked by default
+	qualityDays = 28
+)
+
+// Check represents a key checked for data-quality issues
+type Check struct {
+	// Key is the document key of the series
+	Key string
+	// Cumulative is set for running totals, checked for decreasing
+	// values, and unset for daily values, checked for negative values
+	// and outliers
+	Cumulative bool
+}
+
+// Issue represents a data-quality issue of a series value
+type Issue struct {
+	// Flag is one of the data-quality flags
+	Flag string `json:"flag"`
+	// Key is the key of the series, empty for stale entries
+	Key string `json:"key,omitempty"`
+	// Date is the date of the value, or the update date of stale entries
+	Date *time.Time `json:"date"`
+	// Value is the flagged value, or the days stale entries are behind
+	Value *float64 `json:"value"`
+	// Expected is the previous cumulative value or the median of
+	// the previous daily values
+	Expected *float64 `json:"expected,omitempty"`
+}
+
+// Quality returns a report of the data-quality issues of each country or
+// region, checked over the last 4 weeks of data by default
+func (d *Dataset) Quality(s store.Store, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if opts.From.IsZero() {
+		to, err := d.endDate(s, opts)
+		if err != nil {
+			return nil, err
+		}
+		optionsList = append(optionsList, From(to.AddDate(0, 0, -qualityDays)), To(endOf(to)), Date(""))
+	}
+
+	// the checked series are aligned to consecutive days
+	if opts.Fill == "" {
+		optionsList = append(optionsList, Fill(FillNone))
+	}
+	optionsList = append(optionsList, Keys(d.checkKeys()), Quality(true))
+	list, err := d.Agg(s, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range list {
+		c, ok := entry.(*Computed)
+		if !ok {
+			continue
+		}
+		issues, _ := c.Values["quality"].([]Issue)
+
+		out := &Computed{Entry: metaOf(c.Entry), Keys: qualityKeys(), Values: make(map[string]interface{})}
+		for _, flag := range Flags {
+			out.Values[flag] = int64(0)
+		}
+		for _, issue := range issues {
+			out.Values[issue.Flag] = out.Values[issue.Flag].(int64) + 1
+		}
+		out.Values["issues"] = int64(len(issues))
+		out.Values["quality"] = issues
+		list[i] = out
+	}
+	return list, nil
+}
+
+// QualityColumns returns the flat columns of the Quality entries, the
+// Meta fields followed by the number of issues of each flag
+func (d *Dataset) QualityColumns() []string {
+	list := d.metaKeys()
+	list = appendKeys(list, "from", "to")
+	list = columns(metaOf(d.NewSeries()), list)
+	keys := qualityKeys()
+	return append(list, keys[:len(keys)-1]...)
+}
+
+// qualityKeys returns the keys of the Quality entries
+func qualityKeys() []string {
+	keys := append([]string{"issues"}, Flags...)
+	return append(keys, "quality")
+}
+
+// checkKeys returns the keys of the dataset Checks
+func (d *Dataset) checkKeys() string {
+	var keys []string
+	for _, c := range d.Checks {
+		keys = append(keys, c.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+// checkQuality returns the data-quality issues of the Agg entries, in
+// date order. Entries are stale if updated more than 3 days before the
+// latest entry, or the latest date of the dataset up to the to date
+func (d *Dataset) checkQuality(s store.Store, list []interface{}, to time.Time) ([][]Issue, error) {
+	out := make([][]Issue, len(list))
+
+	latest, err := d.Latest(s)
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsZero() && to.Before(latest) {
+		latest = bucket(to, IntervalDay)
+	}
+	for _, entry := range list {
+		if t := updatedAt(entry); t != nil && t.After(latest) {
+			latest = *t
+		}
+	}
+
+	for i, entry := range list {
+		issues := []Issue{}
+
+		fields := fieldValues(reflect.ValueOf(entry))
+		var dates []*time.Time
+		if v, ok := fields["date"]; ok {
+			dates, _ = v.Interface().([]*time.Time)
+		}
+		for _, c := range d.Checks {
+			if v, ok := fields[c.Key]; !ok || v.Kind() != reflect.Slice || v.IsNil() {
+				continue
+			}
+			f := frameOf(entry, []string{c.Key})
+			issues = append(issues, check(c, f.Get(c.Key), dates)...)
+		}
+
+		if t := updatedAt(entry); t != nil && latest.Sub(*t) > staleDays*24*time.Hour {
+			days := math.Floor(latest.Sub(*t).Hours() / 24)
+			issues = append(issues, Issue{Flag: FlagStale, Date: t, Value: &days})
+		}
+
+		sort.SliceStable(issues, func(a, b int) bool {
+			if issues[a].Date == nil || issues[b].Date == nil {
+				return issues[b].Date == nil && issues[a].Date != nil
+			}
+			return issues[a].Date.Before(*issues[b].Date)
+		})
+		out[i] = issues
+	}
+	return out, nil
+}
+
+// check returns the issues of a series
+func check(c Check, values []*float64, dates []*time.Time) []Issue {
+	var issues []Issue
+	issue := func(flag string, i int, expected *float64) {
+		var date *time.Time
+		if i < len(dates) {
+			date = dates[i]
+		}
+		issues = append(issues, Issue{Flag: flag, Key: c.Key, Date: date, Value: values[i], Expected: expected})
+	}
+
+	var prev *float64
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if c.Cumulative {
+			if prev != nil && *v < *prev {
+				issue(FlagDecreasing, i, prev)
+			}
+			prev = v
+			continue
+		}
+
+		if *v < 0 {
+			issue(FlagNegative, i, nil)
+			continue
+		}
+		start := i - outlierDays
+		if start < 0 {
+			start = 0
+		}
+		if m := median(values[start:i]); m != nil {
+			if *v > outlierFactor**m && *v-*m >= outlierDiff {
+				issue(FlagOutlier, i, m)
+			}
+		}
+	}
+	return issues
+}
+
+// median returns the median of the values of a series, nil if there
+// are not enough values
+func median(values []*float64) *float64 {
+	var list []float64
+	for _, v := range values {
+		if v != nil {
+			list = append(list, *v)
+		}
+	}
+	if len(list) < outlierMin {
+		return nil
+	}
+
+	sort.Float64s(list)
+	m := list[len(list)/2]
+	if len(list)%2 == 0 {
+		m = (list[len(list)/2-1] + m) / 2
+	}
+	return &m
+}
+
+// updatedAt returns the last update date of an entry
+func updatedAt(entry interface{}) *time.Time {
+	v, ok := fieldValues(reflect.ValueOf(entry))["last_updated_at"]
+	if !ok {
+		return nil
+	}
+	t, _ := v.Interface().(*time.Time)
+	return t
+}
+
+// withQuality adds the issues to the Agg entries as the `quality` key
+func withQuality(list []interface{}, issues [][]Issue) []interface{} {
+	for i, entry := range list {
+		c, ok := entry.(*Computed)
+		if !ok {
+			c = &Computed{Entry: entry, Values: make(map[string]interface{})}
+		}
+		c.Keys = append(c.Keys, "quality")
+		c.Values["quality"] = issues[i]
+		list[i] = c
+	}
+	return list
+}
@@ -0,0 +1,154 @@
+package dataset
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCheck(t *testing.T) {
+	nan := math.NaN()
+	dates := make([]*time.Time, 12)
+	for i := range dates {
+		date := day(i)
+		dates[i] = &date
+	}
+
+	tests := []struct {
+		name   string
+		check  Check
+		values []*float64
+		want   []string
+	}{
+		{
+			name:   "negative",
+			check:  Check{Key: "new_cases"},
+			values: values(1, -2, 3),
+			want:   []string{"negative_value 03-02 -2 <nil>"},
+		},
+		{
+			name:   "outlier",
+			check:  Check{Key: "new_cases"},
+			values: values(10, 10, nan, 10, 10, 10, 10, 10, 100, 10),
+			want:   []string{"outlier 03-09 100 10"},
+		},
+		{
+			// the median needs a week of values
+			name:   "outlier without history",
+			check:  Check{Key: "new_cases"},
+			values: values(10, 10, 10, 10, 10, 10, 100),
+		},
+		{
+			// small counts are not flagged
+			name:   "outlier of small counts",
+			check:  Check{Key: "new_cases"},
+			values: values(1, 1, 1, 1, 1, 1, 1, 8),
+		},
+		{
+			// cumulative values are compared to the previous value set
+			name:   "decreasing",
+			check:  Check{Key: "cases", Cumulative: true},
+			values: values(10, 12, nan, 11, 11, -1),
+			want:   []string{"decreasing_cumulative 03-04 11 12", "decreasing_cumulative 03-06 -1 11"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range check(tt.check, tt.values, dates) {
+				got = append(got, fmt.Sprintf("%s %s %v %v",
+					issue.Flag, issue.Date.Format("01-02"), *issue.Value, floats([]*float64{issue.Expected})[0]))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuality(t *testing.T) {
+	d := newTestDataset()
+	var docs []bson.M
+	for i := 0; i < 10; i++ {
+		a := bson.M{"new_cases": int64(10), "cases": int64(10 * (i + 1))}
+		switch i {
+		case 5:
+			a["cases"] = int64(1)
+		case 8:
+			a["new_cases"] = int64(100)
+		case 9:
+			a["new_cases"] = int64(-1)
+		}
+		// B stopped updating after the second day
+		docs = append(docs,
+			doc("A", i, a),
+			doc("B", i, bson.M{"new_cases": int64(10), "last_updated_at": day(2)}),
+		)
+	}
+	s := newTestStore(t, docs...)
+
+	list, err := d.Quality(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d entries, want 2", len(list))
+	}
+
+	tests := []struct {
+		entry int
+		want  map[string]interface{}
+	}{
+		{0, map[string]interface{}{"issues": int64(3), FlagNegative: int64(1), FlagDecreasing: int64(1), FlagOutlier: int64(1), FlagStale: int64(0)}},
+		{1, map[string]interface{}{"issues": int64(1), FlagNegative: int64(0), FlagDecreasing: int64(0), FlagOutlier: int64(0), FlagStale: int64(1)}},
+	}
+	for _, tt := range tests {
+		c := list[tt.entry].(*Computed)
+		for key, want := range tt.want {
+			if got := c.Values[key]; got != want {
+				t.Errorf("%d %s = %v, want %v", tt.entry, key, got, want)
+			}
+		}
+	}
+
+	// issues are sorted by date
+	var flags []string
+	for _, issue := range list[0].(*Computed).Values["quality"].([]Issue) {
+		flags = append(flags, issue.Flag)
+	}
+	if want := []string{FlagDecreasing, FlagOutlier, FlagNegative}; !reflect.DeepEqual(flags, want) {
+		t.Errorf("flags = %v, want %v", flags, want)
+	}
+	// stale entries are flagged with the days behind the latest update
+	if issue := list[1].(*Computed).Values["quality"].([]Issue)[0]; *issue.Value != 8 {
+		t.Errorf("stale days = %v, want 8", *issue.Value)
+	}
+
+	// a single stale entry is behind the latest date of the dataset
+	list, err = d.Quality(s, IDs("B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("got %d entries, want 1", len(list))
+	}
+	if got := list[0].(*Computed).Values[FlagStale]; got != int64(1) {
+		t.Errorf("%s = %v, want 1", FlagStale, got)
+	}
+	if issue := list[0].(*Computed).Values["quality"].([]Issue)[0]; *issue.Value != 7 {
+		t.Errorf("stale days = %v, want 7", *issue.Value)
+	}
+
+	// entries are checked up to the end of the range
+	list, err = d.Quality(s, IDs("B"), From(day(0)), To(endOf(day(4))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list[0].(*Computed).Values[FlagStale]; got != int64(0) {
+		t.Errorf("%s = %v, want 0 up to the fifth day", FlagStale, got)
+	}
+}
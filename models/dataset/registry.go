@@ -0,0 +1,54 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+// Registry holds the datasets served by the API, in registration order
+type Registry struct {
+	datasets []*Dataset
+	byName   map[string]*Dataset
+}
+
+// NewRegistry creates a new registry with the given datasets
+func NewRegistry(datasets ...*Dataset) *Registry {
+	r := &Registry{
+		byName: make(map[string]*Dataset),
+	}
+	for _, d := range datasets {
+		if err := r.Register(d); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds a dataset to the registry
+func (r *Registry) Register(d *Dataset) error {
+	if _, ok := r.byName[d.Name]; ok {
+		return fmt.Errorf("dataset %s already registered", d.Name)
+	}
+	r.datasets = append(r.datasets, d)
+	r.byName[d.Name] = d
+	return nil
+}
+
+// Get returns a registered dataset by name
+func (r *Registry) Get(name string) (*Dataset, bool) {
+	d, ok := r.byName[name]
+	return d, ok
+}
+
+// All returns all registered datasets
+func (r *Registry) All() []*Dataset {
+	return r.datasets
+}
+
+// Default returns the first registered dataset, served under the
+// bare `/agg` and `/total` routes
+func (r *Registry) Default() *Dataset {
+	if len(r.datasets) == 0 {
+		return nil
+	}
+	return r.datasets[0]
+}
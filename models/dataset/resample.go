@@ -0,0 +1,162 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// Resampling intervals
+const (
+	IntervalDay   = store.Day
+	IntervalWeek  = store.Week
+	IntervalMonth = store.Month
+)
+
+// Intervals lists the supported resampling intervals
+var Intervals = []string{IntervalDay, IntervalWeek, IntervalMonth}
+
+// bucket returns the start of the interval bucket of a date, see
+// store.Bucket
+func bucket(t time.Time, interval string) time.Time {
+	return store.Bucket(t, interval)
+}
+
+// isDaily checks if a key holds daily values, summed over intervals
+// as `new_*` keys and the keys summed in Sum
+func (d *Dataset) isDaily(key string) bool {
+	if strings.HasPrefix(key, "new_") {
+		return true
+	}
+	for _, f := range d.SumFields {
+		if f.Op == store.Sum && f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// resample groups the daily values of an Agg entry by interval, dated
+// by the start of each bucket. Daily values are summed, other values
+// take the last value of the bucket
+func (d *Dataset) resample(entry interface{}, interval string) {
+	if interval == "" || interval == IntervalDay {
+		return
+	}
+
+	fields := fieldValues(reflect.ValueOf(entry))
+	dates, ok := fields["date"]
+	if !ok || dates.Len() == 0 {
+		return
+	}
+
+	// bucket index of each date
+	var (
+		starts []time.Time
+		index  = make([]int, dates.Len())
+	)
+	for i := 0; i < dates.Len(); i++ {
+		date, ok := dates.Index(i).Interface().(*time.Time)
+		if !ok || date == nil {
+			index[i] = -1
+			continue
+		}
+		start := bucket(*date, interval)
+		if len(starts) == 0 || !starts[len(starts)-1].Equal(start) {
+			starts = append(starts, start)
+		}
+		index[i] = len(starts) - 1
+	}
+
+	for key, v := range fields {
+		if v.Kind() != reflect.Slice || v.IsNil() || key == "sources" {
+			continue
+		}
+
+		out := reflect.MakeSlice(v.Type(), len(starts), len(starts))
+		if key == "date" {
+			for i := range starts {
+				start := starts[i]
+				out.Index(i).Set(reflect.ValueOf(&start))
+			}
+			v.Set(out)
+			continue
+		}
+
+		daily := d.isDaily(key)
+		for i := 0; i < v.Len() && i < len(index); i++ {
+			value, b := v.Index(i), index[i]
+			if b < 0 || value.IsNil() {
+				continue
+			}
+			if !daily || out.Index(b).IsNil() {
+				n := reflect.New(value.Type().Elem())
+				n.Elem().Set(value.Elem())
+				out.Index(b).Set(n)
+				continue
+			}
+
+			sum := out.Index(b).Elem()
+			switch sum.Kind() {
+			case reflect.Int64:
+				sum.SetInt(sum.Int() + value.Elem().Int())
+			case reflect.Float64:
+				sum.SetFloat(sum.Float() + value.Elem().Float())
+			}
+		}
+		v.Set(out)
+	}
+}
+
+// sumIntervals computes the Sum entries of each interval bucket in the
+// date range, grouped by the rollup and sorted by bucket. The fields
+// must include the `from` date of each bucket
+func (d *Dataset) sumIntervals(s store.Store, q store.Query, fields []Field, interval, groupBy string) ([]interface{}, error) {
+	// date range of the buckets, the current date if not set
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := s.Aggregate(ctx, d.Collection, q, store.Group{
+		By:       d.GroupBy,
+		Fields:   fields,
+		Sort:     d.IDField,
+		Interval: interval,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "db.%s.sum()", d.Collection)
+	}
+	entries, err := decode(ctx, c, d.NewTotal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "db.%s.sum()", d.Collection)
+	}
+
+	// rollups merge the entries of each bucket
+	var list, run []interface{}
+	var start time.Time
+	for _, entry := range entries {
+		var b time.Time
+		if from := timeValues(fieldValues(reflect.ValueOf(entry))["from"]); len(from) > 0 {
+			b = bucket(from[0], interval)
+		}
+		if len(run) > 0 && !b.Equal(start) {
+			list = append(list, d.groupEntries(run, groupBy, d.NewTotal)...)
+			run = nil
+		}
+		start, run = b, append(run, entry)
+	}
+	if len(run) > 0 {
+		list = append(list, d.groupEntries(run, groupBy, d.NewTotal)...)
+	}
+	return list, nil
+}
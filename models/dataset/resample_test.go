@@ -0,0 +1,66 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSumIntervals(t *testing.T) {
+	d := newTestDataset()
+	// two weeks and three days from monday 2021-03-01
+	var docs []bson.M
+	for i := 0; i < 17; i++ {
+		docs = append(docs,
+			doc("B", i, bson.M{"new_cases": int64(2), "cases": int64(2 * (i + 1))}),
+			doc("A", i, bson.M{"new_cases": int64(1), "cases": int64(i + 1)}),
+		)
+	}
+	s := newTestStore(t, docs...)
+
+	tests := []struct {
+		name string
+		opts []func(*ListOptions)
+		want string
+	}{
+		{
+			name: "weeks",
+			opts: []func(*ListOptions){From(day(0)), To(endOf(day(16))), Interval(IntervalWeek)},
+			want: "A 03-01 7 7,B 03-01 14 14,A 03-08 7 14,B 03-08 14 28,A 03-15 3 17,B 03-15 6 34",
+		},
+		{
+			// the first bucket starts on the from date
+			name: "partial weeks",
+			opts: []func(*ListOptions){IDs("A"), From(day(5)), To(endOf(day(9))), Interval(IntervalWeek)},
+			want: "A 03-06 2 7,A 03-08 3 10",
+		},
+		{
+			name: "months",
+			opts: []func(*ListOptions){From(day(0)), To(endOf(day(16))), Interval(IntervalMonth)},
+			want: "A 03-01 17 17,B 03-01 34 34",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := d.Sum(s, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range list {
+				fields := entryValues(entry)
+				from := timeValues(fields["from"])
+				if len(from) != 1 {
+					t.Fatalf("missing from date: %v", entry)
+				}
+				got = append(got, fmt.Sprintf("%s %s %v %v",
+					stringValues(fields["id"])[0], from[0].Format("01-02"), *series(t, entry, "cases")[0], *series(t, entry, "total_cases")[0]))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("got %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+// Rolling functions
+const (
+	RollingMean = "mean"
+	RollingSum  = "sum"
+)
+
+// Rolling window alignments
+const (
+	// AlignTrailing computes each value over the window ending on it
+	AlignTrailing = "trailing"
+	// AlignCentered computes each value over the window centered on it
+	AlignCentered = "centered"
+)
+
+// Partial window handling, for the values without a full window
+// at the edges of the series
+const (
+	// PartialNull leaves the values of partial windows null
+	PartialNull = "null"
+	// PartialShrink computes the values of partial windows over the
+	// available values
+	PartialShrink = "shrink"
+)
+
+// Rolling returns a transform computing the rolling mean or sum of each
+// numeric key over a window of consecutive values, as
+// `<key>_rolling_<fn>_<window>`. Missing values are skipped, and windows
+// without any values are null
+func Rolling(fn string, window int, align, partial string) Transform {
+	return func(f *Frame) {
+		for _, key := range f.Keys {
+			f.Set(fmt.Sprintf("%s_rolling_%s_%d", key, fn, window), rolling(f.Get(key), fn, window, align, partial))
+		}
+	}
+}
+
+// rolling computes the rolling function of a series
+func rolling(values []*float64, fn string, window int, align, partial string) []*float64 {
+	out := make([]*float64, len(values))
+	for i := range values {
+		start := i - window + 1
+		if align == AlignCentered {
+			start = i - window/2
+		}
+		end := start + window - 1
+
+		if start < 0 || end >= len(values) {
+			if partial != PartialShrink {
+				continue
+			}
+			if start < 0 {
+				start = 0
+			}
+			if end >= len(values) {
+				end = len(values) - 1
+			}
+		}
+
+		var sum float64
+		var n int
+		for _, v := range values[start : end+1] {
+			if v != nil {
+				sum += *v
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		if fn == RollingMean {
+			sum /= float64(n)
+		}
+		out[i] = &sum
+	}
+	return out
+}
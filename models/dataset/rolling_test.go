@@ -0,0 +1,130 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// values returns a series of the numbers, NaN for missing values
+func values(list ...float64) []*float64 {
+	out := make([]*float64, len(list))
+	for i := range list {
+		if !math.IsNaN(list[i]) {
+			out[i] = &list[i]
+		}
+	}
+	return out
+}
+
+func TestRolling(t *testing.T) {
+	nan := math.NaN()
+	list := values(1, 2, nan, 4, 5)
+
+	tests := []struct {
+		name    string
+		fn      string
+		align   string
+		partial string
+		want    []interface{}
+	}{
+		{"trailing mean", RollingMean, AlignTrailing, PartialNull, []interface{}{nil, nil, 1.5, 3.0, 4.5}},
+		{"trailing sum", RollingSum, AlignTrailing, PartialNull, []interface{}{nil, nil, 3.0, 6.0, 9.0}},
+		{"trailing shrink", RollingSum, AlignTrailing, PartialShrink, []interface{}{1.0, 3.0, 3.0, 6.0, 9.0}},
+		{"centered mean", RollingMean, AlignCentered, PartialNull, []interface{}{nil, 1.5, 3.0, 4.5, nil}},
+		{"centered shrink", RollingMean, AlignCentered, PartialShrink, []interface{}{1.5, 1.5, 3.0, 4.5, 4.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := floats(rolling(list, tt.fn, 3, tt.align, tt.partial)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// windows without any value are null
+	if got := floats(rolling(values(1, nan, nan, nan), RollingSum, 2, AlignTrailing, PartialNull)); !reflect.DeepEqual(got, []interface{}{nil, 1.0, nil, nil}) {
+		t.Errorf("got %v, want null empty windows", got)
+	}
+}
+
+func TestListRolling(t *testing.T) {
+	d := newTestDataset()
+	// raw records of the ids interleaved by date
+	var docs []bson.M
+	for i := 0; i < 4; i++ {
+		docs = append(docs,
+			doc("A", i, bson.M{"new_cases": int64(i + 1)}),
+			doc("B", i, bson.M{"new_cases": int64(10 * (i + 1))}),
+		)
+	}
+	s := newTestStore(t, docs...)
+
+	list, err := d.List(s, Keys("new_cases"), From(day(0)), To(endOf(day(3))), Transforms(Rolling(RollingSum, 2, AlignTrailing, PartialNull)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// records are windowed per id in date order
+	got := make(map[string][]interface{})
+	for _, entry := range list {
+		id := stringValues(entryValues(entry)["id"])
+		if len(id) != 1 {
+			t.Fatalf("missing id: %v", entry)
+		}
+		got[id[0]] = append(got[id[0]], floats(series(t, entry, "new_cases_rolling_sum_2"))...)
+	}
+	want := map[string][]interface{}{
+		"A": {nil, 3.0, 5.0, 7.0},
+		"B": {nil, 30.0, 50.0, 70.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestListRollingGaps(t *testing.T) {
+	d := newTestDataset()
+	// the third day is not reported
+	s := newTestStore(t,
+		doc("A", 0, bson.M{"new_cases": int64(1)}),
+		doc("A", 1, bson.M{"new_cases": int64(2)}),
+		doc("A", 3, bson.M{"new_cases": int64(4)}),
+	)
+
+	list, err := d.List(s, Keys("new_cases"), From(day(0)), To(endOf(day(3))), Transforms(Rolling(RollingSum, 2, AlignTrailing, PartialNull)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// windows span days, not records
+	var got []interface{}
+	for _, entry := range list {
+		got = append(got, floats(series(t, entry, "new_cases_rolling_sum_2"))...)
+	}
+	if want := []interface{}{nil, 3.0, 4.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAggRolling(t *testing.T) {
+	d := newTestDataset()
+	s := newTestStore(t,
+		doc("A", 0, bson.M{"new_cases": int64(1)}),
+		doc("A", 1, bson.M{"new_cases": int64(2)}),
+		doc("A", 2, bson.M{"new_cases": int64(6)}),
+	)
+
+	list, err := d.Agg(s, IDs("A"), Keys("new_cases"), From(day(0)), To(endOf(day(2))), Transforms(Rolling(RollingMean, 3, AlignTrailing, PartialShrink)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := floats(series(t, list[0], "new_cases_rolling_mean_3")), []interface{}{1.0, 1.5, 3.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// the series transformed are returned along
+	if got, want := floats(series(t, list[0], "new_cases")), []interface{}{1.0, 2.0, 6.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,395 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rollup describes a grouping of the Agg and Sum entries of a dataset
+// into larger areas, e.g. the regions of a state
+type Rollup struct {
+	// Name is the group_by value of the rollup
+	Name string
+	// Key is the entry key the entries are grouped by, empty to
+	// group all entries together
+	Key string
+	// Lookup returns the group of an entry by its IDField value, if
+	// set, the group is stored to the Key of the merged entries
+	Lookup func(id string) string
+	// ID is the IDField value of the groups, if all entries are
+	// grouped together
+	ID string
+	// Keep lists the Meta keys describing the groups, kept if equal
+	// for all the entries of a group, other Meta keys are cleared
+	Keep []string
+}
+
+// rollup returns a dataset rollup by name
+func (d *Dataset) rollup(name string) (Rollup, bool) {
+	for _, r := range d.Rollups {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rollup{}, false
+}
+
+// groupKeys returns the keys the groups of the requested rollup are
+// stored to, following the Meta keys
+func (d *Dataset) groupKeys(opts ListOptions) []string {
+	if r, ok := d.rollup(opts.GroupBy); ok && r.Lookup != nil {
+		return []string{r.Key}
+	}
+	return nil
+}
+
+// rollupNames returns the names of the dataset rollups
+func (d *Dataset) rollupNames() []string {
+	var list []string
+	for _, r := range d.Rollups {
+		list = append(list, r.Name)
+	}
+	return list
+}
+
+// groupEntries merges the Agg or Sum entries of each group of the rollup,
+// sorted by the group key. Numeric values are summed, Agg series by date,
+// and the Weights ratios are averaged by their weight. The Keep values
+// are kept if equal for all the entries of a group, sources are merged
+// and dates span the dates of the group
+func (d *Dataset) groupEntries(list []interface{}, name string, newEntry func() interface{}) []interface{} {
+	r, ok := d.rollup(name)
+	if !ok || len(list) == 0 {
+		return list
+	}
+
+	var (
+		keys   []string
+		groups = make(map[string][]interface{})
+	)
+	for _, entry := range list {
+		key := ""
+		fields := fieldValues(reflect.ValueOf(entry))
+		if r.Lookup != nil {
+			if v, ok := fields[d.IDField]; ok {
+				key = r.Lookup(format(v.Interface()))
+			}
+		} else if r.Key != "" {
+			if v, ok := fields[r.Key]; ok {
+				key = format(v.Interface())
+			}
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+	sort.Strings(keys)
+
+	out := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		entry := newEntry()
+		d.merge(entry, groups[key], r.Keep)
+		if r.Key == "" && r.ID != "" {
+			setString(entry, d.IDField, r.ID)
+		}
+		if r.Lookup != nil && key != "" {
+			setString(entry, r.Key, key)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// merge merges the values of the entries to the entry
+func (d *Dataset) merge(entry interface{}, members []interface{}, keep []string) {
+	fields := fieldValues(reflect.ValueOf(entry))
+	values := make([]map[string]reflect.Value, len(members))
+	for i, m := range members {
+		values[i] = fieldValues(reflect.ValueOf(m))
+	}
+
+	// series are merged by date, pos maps the values of each member
+	// to the dates of the group
+	dates, pos := mergeDates(values)
+	if v, ok := fields["date"]; ok && v.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(v.Type(), len(dates), len(dates))
+		for i := range dates {
+			date := dates[i]
+			out.Index(i).Set(reflect.ValueOf(&date))
+		}
+		v.Set(out)
+	}
+
+	for name, v := range fields {
+		if name == "date" || name == "distance" {
+			continue
+		}
+		weight := d.Weights[name]
+
+		switch {
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+			// merge lists, e.g. sources
+			out := reflect.MakeSlice(v.Type(), 0, 0)
+			for _, m := range values {
+				mv, ok := m[name]
+				if !ok {
+					continue
+				}
+				for i := 0; i < mv.Len(); i++ {
+					if !containsValue(out, mv.Index(i)) {
+						out = reflect.Append(out, mv.Index(i))
+					}
+				}
+			}
+			v.Set(out)
+
+		case v.Kind() == reflect.Slice && isNumeric(v.Type()):
+			if !anySet(values, name) {
+				continue
+			}
+			out := reflect.MakeSlice(v.Type(), len(dates), len(dates))
+			sums := make([]*float64, len(dates))
+			weights := make([]float64, len(dates))
+			for j, m := range values {
+				mv, ok := m[name]
+				if !ok {
+					continue
+				}
+				for i := 0; i < mv.Len() && i < len(pos[j]); i++ {
+					x := toFloat(mv.Index(i))
+					if x == nil || pos[j][i] < 0 {
+						continue
+					}
+					w := 1.0
+					if weight != "" {
+						if w = weightOf(m, weight, i); w == 0 {
+							continue
+						}
+						weights[pos[j][i]] += w
+					}
+					sums[pos[j][i]] = addFloat(sums[pos[j][i]], *x*w)
+				}
+			}
+			for i, sum := range sums {
+				if sum == nil || weight != "" && weights[i] == 0 {
+					continue
+				}
+				if weight != "" {
+					*sum /= weights[i]
+				}
+				out.Index(i).Set(numberOf(v.Type().Elem(), *sum))
+			}
+			v.Set(out)
+
+		case v.Kind() == reflect.Ptr && isNumeric(v.Type()):
+			// numeric Meta values describe a single entry, e.g. uid,
+			// except the population summed over the group
+			if d.isMetaKey(name) && name != "population" {
+				continue
+			}
+			var sum *float64
+			var weights float64
+			for _, m := range values {
+				mv, ok := m[name]
+				if !ok {
+					continue
+				}
+				x := toFloat(mv)
+				if x == nil {
+					continue
+				}
+				w := 1.0
+				if weight != "" {
+					if w = weightOf(m, weight, 0); w == 0 {
+						continue
+					}
+					weights += w
+				}
+				sum = addFloat(sum, *x*w)
+			}
+			if sum == nil || weight != "" && weights == 0 {
+				continue
+			}
+			if weight != "" {
+				*sum /= weights
+			}
+			v.Set(numberOf(v.Type(), *sum))
+
+		case v.Type() == reflect.TypeOf(&time.Time{}):
+			// dates span the group, from the first date to the last
+			var t *time.Time
+			for _, m := range values {
+				mv, ok := m[name]
+				if !ok || mv.IsNil() {
+					continue
+				}
+				mt := mv.Interface().(*time.Time)
+				if t == nil || name == "from" && mt.Before(*t) || name != "from" && mt.After(*t) {
+					t = mt
+				}
+			}
+			if t != nil {
+				v.Set(reflect.ValueOf(t))
+			}
+
+		case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.String:
+			// keep the values describing the group, if shared by
+			// all the entries of the group
+			if !IsValidKey(name, keep) {
+				continue
+			}
+			var shared reflect.Value
+			for i, m := range values {
+				mv, ok := m[name]
+				if !ok || mv.IsNil() || i > 0 && (!shared.IsValid() || mv.Elem().String() != shared.Elem().String()) {
+					shared = reflect.Value{}
+					break
+				}
+				shared = mv
+			}
+			if shared.IsValid() {
+				v.Set(shared)
+			}
+		}
+	}
+}
+
+// setString sets a string pointer field of an entry by json key
+func setString(entry interface{}, key, value string) {
+	v, ok := fieldValues(reflect.ValueOf(entry))[key]
+	if !ok || v.Kind() != reflect.Ptr || v.Type().Elem().Kind() != reflect.String {
+		return
+	}
+	s := reflect.New(v.Type().Elem())
+	s.Elem().SetString(value)
+	v.Set(s)
+}
+
+// anySet checks if any of the entries holds a series of the key
+func anySet(values []map[string]reflect.Value, key string) bool {
+	for _, m := range values {
+		if v, ok := m[key]; ok && !v.IsNil() {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDates returns the sorted dates of the series of the entries,
+// and the position of each value of each entry in the dates
+func mergeDates(values []map[string]reflect.Value) ([]time.Time, [][]int) {
+	var dates []time.Time
+	seen := make(map[time.Time]bool)
+	for _, m := range values {
+		v, ok := m["date"]
+		if !ok || v.Kind() != reflect.Slice {
+			continue
+		}
+		for i := 0; i < v.Len(); i++ {
+			if t, ok := v.Index(i).Interface().(*time.Time); ok && t != nil && !seen[*t] {
+				seen[*t] = true
+				dates = append(dates, *t)
+			}
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	index := make(map[time.Time]int)
+	for i, t := range dates {
+		index[t] = i
+	}
+	pos := make([][]int, len(values))
+	for j, m := range values {
+		v, ok := m["date"]
+		if !ok || v.Kind() != reflect.Slice {
+			continue
+		}
+		pos[j] = make([]int, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			pos[j][i] = -1
+			if t, ok := v.Index(i).Interface().(*time.Time); ok && t != nil {
+				pos[j][i] = index[*t]
+			}
+		}
+	}
+	return dates, pos
+}
+
+// weightOf returns the weight of the i-th value of an entry, the value
+// of the weight key or its i-th value for series
+func weightOf(m map[string]reflect.Value, key string, i int) float64 {
+	v, ok := m[key]
+	if !ok {
+		return 0
+	}
+	if v.Kind() == reflect.Slice {
+		if i >= v.Len() {
+			return 0
+		}
+		v = v.Index(i)
+	}
+	if w := toFloat(v); w != nil && *w > 0 {
+		return *w
+	}
+	return 0
+}
+
+// addFloat adds a value to a sum, nil if not set
+func addFloat(sum *float64, v float64) *float64 {
+	if sum == nil {
+		return &v
+	}
+	*sum += v
+	return sum
+}
+
+// numberOf returns a pointer of the numeric pointer type holding v,
+// rounded for integers
+func numberOf(t reflect.Type, v float64) reflect.Value {
+	n := reflect.New(t.Elem())
+	switch t.Elem().Kind() {
+	case reflect.Int64:
+		n.Elem().SetInt(int64(math.Round(v)))
+	case reflect.Float64:
+		n.Elem().SetFloat(v)
+	}
+	return n
+}
+
+// containsValue checks if a string is in a list value
+func containsValue(list reflect.Value, v reflect.Value) bool {
+	for i := 0; i < list.Len(); i++ {
+		if list.Index(i).String() == v.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// region returns the IDField values of a dataset region, names are
+// matched case insensitively
+func (d *Dataset) region(name string) ([]string, bool) {
+	if name == "" {
+		return nil, false
+	}
+	for key, ids := range d.Regions {
+		if strings.EqualFold(key, name) {
+			return ids, true
+		}
+	}
+	return nil, false
+}
+
+// regionNames returns the sorted names of the dataset regions
+func (d *Dataset) regionNames() []string {
+	var list []string
+	for key := range d.Regions {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,289 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+)
+
+// SerialInterval describes the gamma distributed serial interval, the
+// days between the symptom onsets of an infector and an infectee
+type SerialInterval struct {
+	Mean float64
+	SD   float64
+}
+
+// DefaultSerialInterval is the COVID-19 serial interval estimated by
+// Nishiura et al. (2020)
+var DefaultSerialInterval = SerialInterval{Mean: 4.7, SD: 2.9}
+
+// Rt estimation defaults, the gamma prior of Rt (mean 5, sd 5) and
+// the 95% credible interval, as in EpiEstim
+const (
+	rtPriorShape = 1.0
+	rtPriorScale = 5.0
+	rtLower      = 0.025
+	rtUpper      = 0.975
+	// rtDays are the days of history used when no from date is set
+	rtDays = 8 * 7
+)
+
+// Weights returns the discrete serial interval distribution of days
+// 0 to n-1, discretised as in EpiEstim `discr_si`, a gamma distribution
+// of the mean - 1 and sd shifted by one day
+func (si SerialInterval) Weights(n int) []float64 {
+	shape := math.Pow((si.Mean-1)/si.SD, 2)
+	scale := si.SD * si.SD / (si.Mean - 1)
+	cdf := func(k, shape float64) float64 {
+		if k <= 0 {
+			return 0
+		}
+		return gammaP(shape, k/scale)
+	}
+
+	w := make([]float64, n)
+	for i := range w {
+		k := float64(i)
+		v := k*cdf(k, shape) + (k-2)*cdf(k-2, shape) - 2*(k-1)*cdf(k-1, shape)
+		v += shape * scale * (2*cdf(k-1, shape+1) - cdf(k-2, shape+1) - cdf(k, shape+1))
+		if v > 0 {
+			w[i] = v
+		}
+	}
+	return w
+}
+
+// Cori estimates the time varying reproduction number of daily incidence
+// with the method of Cori et al. (2013), over sliding windows of days
+// ending on each day. Returns the posterior mean and the bounds of the
+// 95% credible interval of each day, null for the first window days and
+// the days without any infectivity. Missing and negative incidence, e.g.
+// from data corrections, is read as zero
+func Cori(incidence []*float64, si SerialInterval, window int) (mean, lower, upper []*float64) {
+	n := len(incidence)
+	mean, lower, upper = make([]*float64, n), make([]*float64, n), make([]*float64, n)
+
+	cases := make([]float64, n)
+	for i, v := range incidence {
+		if v != nil && *v > 0 {
+			cases[i] = *v
+		}
+	}
+
+	// the total infectivity of each day, the incidence of the previous
+	// days weighted by the serial interval
+	w := si.Weights(n)
+	lambda := make([]float64, n)
+	for i := range lambda {
+		for k := 1; k <= i; k++ {
+			lambda[i] += cases[i-k] * w[k]
+		}
+	}
+
+	for i := window; i < n; i++ {
+		var sumCases, sumLambda float64
+		for s := i - window + 1; s <= i; s++ {
+			sumCases += cases[s]
+			sumLambda += lambda[s]
+		}
+		if sumLambda == 0 {
+			continue
+		}
+
+		// gamma posterior of Rt
+		shape := rtPriorShape + sumCases
+		scale := 1 / (1/rtPriorScale + sumLambda)
+		m := shape * scale
+		lo := gammaQuantile(rtLower, shape) * scale
+		hi := gammaQuantile(rtUpper, shape) * scale
+		mean[i], lower[i], upper[i] = &m, &lo, &hi
+	}
+	return mean, lower, upper
+}
+
+// Rt estimates the reproduction number of each group from the Incidence
+// series, see Cori. If no from date is set, the series start 8 weeks
+// before the to date, or before today
+func (d *Dataset) Rt(s store.Store, si SerialInterval, window int, optionsList ...func(*ListOptions)) ([]interface{}, error) {
+	opts := d.parseOpts(optionsList)
+	if opts.From.IsZero() {
+		to, err := d.endDate(s, opts)
+		if err != nil {
+			return nil, err
+		}
+		optionsList = append(optionsList, From(to.AddDate(0, 0, -rtDays)), To(endOf(to)), Date(""))
+	}
+
+	// the infectivity is summed over consecutive days, unreported
+	// days are read as zero incidence unless filled otherwise
+	if opts.Fill == "" {
+		optionsList = append(optionsList, Fill(FillNone))
+	}
+	optionsList = append(optionsList, Keys(d.Incidence))
+	list, err := d.Agg(s, optionsList...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range list {
+		fields := fieldValues(reflect.ValueOf(entry))
+		f := frameOf(entry, []string{d.Incidence})
+		mean, lower, upper := Cori(f.Get(d.Incidence), si, window)
+
+		c := &Computed{
+			Entry: metaOf(entry),
+			Keys:  d.rtKeys(),
+			Values: map[string]interface{}{
+				"rt":       mean,
+				"rt_lower": lower,
+				"rt_upper": upper,
+			},
+		}
+		for _, key := range []string{"date", d.Incidence} {
+			if v, ok := fields[key]; ok {
+				c.Values[key] = v.Interface()
+			}
+		}
+		list[i] = c
+	}
+	return list, nil
+}
+
+// RtColumns returns the flat columns of the Rt entries, the Meta fields
+// followed by the date, the incidence and the estimates
+func (d *Dataset) RtColumns() []string {
+	list := d.metaKeys()
+	list = appendKeys(list, "from", "to")
+	list = columns(metaOf(d.NewSeries()), list)
+	return append(list, d.rtKeys()...)
+}
+
+// rtKeys returns the keys of the Rt entries following the Meta
+func (d *Dataset) rtKeys() []string {
+	return []string{"date", d.Incidence, "rt", "rt_lower", "rt_upper"}
+}
+
+// gammaQuantile returns the p quantile of the gamma distribution of
+// the shape and a unit scale, by bisection
+func gammaQuantile(p, shape float64) float64 {
+	lo, hi := 0.0, shape+1
+	for gammaP(shape, hi) < p {
+		lo, hi = hi, hi*2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if gammaP(shape, mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// gammaLargeShape is the shape from which gammaP is computed by
+// quadrature, the series and continued fraction converge too slowly
+const gammaLargeShape = 100
+
+// gammaP returns the regularized lower incomplete gamma function P(a, x),
+// the cdf of the gamma distribution of shape a and a unit scale, computed
+// by quadrature for large shapes, by its series for x < a+1 and its
+// continued fraction otherwise
+func gammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if a >= gammaLargeShape {
+		return gammaPLarge(a, x)
+	}
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lg)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1.0; n < 1000; n++ {
+			term *= x / (a + n)
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return sum * prefix
+	}
+
+	// modified Lentz's method
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1.0; n < 1000; n++ {
+		an := -n * (n - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
+
+// gaussNodes and gaussWeights are the nodes and weights of the lower
+// half of the 36 point Gauss-Legendre quadrature, shifted to [0, 1]
+var (
+	gaussNodes = []float64{
+		0.0021695375159141994, 0.011413521097787704, 0.027972308950302116,
+		0.05172701560049242, 0.08250222548434094, 0.12007019910960293,
+		0.1641528330075247, 0.21442376986779343, 0.27051082840644336,
+		0.331998763414479, 0.39843234186401943, 0.46931971407375483,
+		0.5441360555665797, 0.6223274528803108, 0.7033150046559717,
+		0.7864991076831345, 0.8712638961906152, 0.9569818015262914,
+	}
+	gaussWeights = []float64{
+		0.005565719664244557, 0.01291594728406542, 0.020181515297735382,
+		0.027298621498568734, 0.03421381077030722, 0.04087575092364488,
+		0.04723508349026599, 0.05324471397775992, 0.0588601442453248,
+		0.06403979735501548, 0.06874532383573641, 0.07294188500565309,
+		0.07659841064587064, 0.07968782891207167, 0.0821872667043397,
+		0.08407821897966182, 0.08534668573933869, 0.08598327567039475,
+	}
+)
+
+// gammaPLarge returns P(a, x) of large shapes, integrating the gamma
+// density from x to a point in its tail, as in Numerical Recipes
+// `gammpapprox`. The density is concentrated within a few sqrt(a) of
+// a-1, so the tail point is far enough for the rest to be negligible
+func gammaPLarge(a, x float64) float64 {
+	a1 := a - 1
+	lna1, sqrta1 := math.Log(a1), math.Sqrt(a1)
+	lg, _ := math.Lgamma(a)
+
+	// the upper tail above the mode, the lower tail below it
+	xu := math.Max(0, math.Min(a1-7.5*sqrta1, x-5*sqrta1))
+	if x > a1 {
+		xu = math.Max(a1+11.5*sqrta1, x+6*sqrta1)
+	}
+
+	var sum float64
+	for i, y := range gaussNodes {
+		t := x + (xu-x)*y
+		sum += gaussWeights[i] * math.Exp(-(t-a1)+a1*(math.Log(t)-lna1))
+	}
+	integral := sum * (xu - x) * math.Exp(a1*(lna1-1)-lg)
+	if x > a1 {
+		// the upper tail Q(a, x)
+		return 1 - integral
+	}
+	return -integral
+}
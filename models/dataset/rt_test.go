@@ -0,0 +1,123 @@
+package dataset
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// approx checks if two values are equal within a relative tolerance
+func approx(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance*math.Max(1, math.Abs(want))
+}
+
+func TestGammaP(t *testing.T) {
+	tests := []struct {
+		a, x float64
+		want float64
+	}{
+		{1, 1, 1 - math.Exp(-1)},
+		{0.5, 2, math.Erf(math.Sqrt2)},
+		{10, 5, 0.031828057306204825},
+		{10, 15, 0.9301463393005914},
+		{100, 100, 0.5132987982791701},
+		{100, 120, 0.9721362601094778},
+		{1000, 950, 0.0550546862307426},
+		{1e6, 1e6, 0.5001329804187331},
+		{1e6, 998000, 0.022696114020409296},
+		{1e6, 1002000, 0.9771959057772165},
+	}
+	for _, tt := range tests {
+		if got := gammaP(tt.a, tt.x); !approx(got, tt.want, 1e-8) {
+			t.Errorf("gammaP(%g, %g) = %v, want %v", tt.a, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestSerialIntervalWeights(t *testing.T) {
+	// EpiEstim discr_si(0:7, 4.7, 2.9)
+	want := []float64{0, 0.0565007869, 0.1780742743, 0.1854180059, 0.1557344076, 0.1207513652, 0.0897176447, 0.0649084185}
+	w := DefaultSerialInterval.Weights(30)
+	for i, v := range want {
+		if !approx(w[i], v, 1e-9) {
+			t.Errorf("w[%d] = %v, want %v", i, w[i], v)
+		}
+	}
+
+	var sum float64
+	for _, v := range w {
+		sum += v
+	}
+	if !approx(sum, 1, 1e-4) {
+		t.Errorf("sum of weights = %v, want 1", sum)
+	}
+}
+
+func TestCori(t *testing.T) {
+	// incidence growing by 10% a day
+	incidence := make([]*float64, 30)
+	for i := range incidence {
+		v := math.Round(10 * math.Pow(1.1, float64(i)))
+		incidence[i] = &v
+	}
+	mean, lower, upper := Cori(incidence, DefaultSerialInterval, 7)
+
+	for i := 0; i < 7; i++ {
+		if mean[i] != nil {
+			t.Errorf("rt[%d] = %v, want null for the first window days", i, *mean[i])
+		}
+	}
+
+	// the posterior mean and 95% credible interval of EpiEstim
+	// estimate_R with the parametric serial interval
+	tests := []struct {
+		day                int
+		mean, lower, upper float64
+	}{
+		{7, 2.3717276638754354, 1.939838254542725, 2.8463760322785685},
+		{29, 1.5143343385893677, 1.4142291621927432, 1.6178140675655497},
+	}
+	for _, tt := range tests {
+		if !approx(*mean[tt.day], tt.mean, 1e-6) || !approx(*lower[tt.day], tt.lower, 1e-6) || !approx(*upper[tt.day], tt.upper, 1e-6) {
+			t.Errorf("rt[%d] = %v [%v, %v], want %v [%v, %v]",
+				tt.day, *mean[tt.day], *lower[tt.day], *upper[tt.day], tt.mean, tt.lower, tt.upper)
+		}
+	}
+}
+
+func TestGammaQuantileLargeShape(t *testing.T) {
+	// the credible interval of a million daily cases over a week
+	shape := 1 + 7e6
+	if lo := gammaQuantile(rtLower, shape); !approx(lo, 6994816.369558034, 1e-9) {
+		t.Errorf("lower quantile = %v, want 6994816.369558034", lo)
+	}
+	if hi := gammaQuantile(rtUpper, shape); !approx(hi, 7005187.524310976, 1e-9) {
+		t.Errorf("upper quantile = %v, want 7005187.524310976", hi)
+	}
+}
+
+func TestRtFillsMissingDays(t *testing.T) {
+	d := newTestDataset()
+	// the 5th day is not reported
+	var docs []bson.M
+	for i := 0; i < 12; i++ {
+		if i == 4 {
+			continue
+		}
+		docs = append(docs, doc("A", i, bson.M{"new_cases": int64(100)}))
+	}
+	s := newTestStore(t, docs...)
+
+	list, err := d.Rt(s, DefaultSerialInterval, 7, IDs("A"), From(day(0)), To(endOf(day(11))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := list[0].(*Computed)
+	if rt := c.Values["rt"].([]*float64); len(rt) != 12 {
+		t.Errorf("got %d estimates, want one for each of the 12 days", len(rt))
+	}
+	if cases := floats(series(t, c, "new_cases")); cases[4] != nil {
+		t.Errorf("new_cases = %v, want null on the unreported day", cases)
+	}
+}
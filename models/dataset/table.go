@@ -0,0 +1,261 @@
+package dataset
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListColumns returns the flat columns of the List entries, the
+// requested keys in request order or all record keys if none
+func (d *Dataset) ListColumns(optionsList ...func(*ListOptions)) []string {
+	opts := d.parseOpts(optionsList)
+	keys := d.keys(opts)
+	if len(keys) > 0 && (opts.Limit > 0 || len(opts.Transforms) > 0) {
+		keys = appendKeys([]string{"date", d.IDField}, keys...)
+	}
+	if len(keys) > 0 && opts.Per != "" {
+		keys = appendKeys(keys, "population")
+	}
+	if len(keys) > 0 && len(opts.Near) > 0 {
+		keys = appendKeys(keys, d.GeoField)
+	}
+	list := withDistance(columns(d.NewRecord(), keys), opts)
+	return append(list, computedKeys(d.numericKeys(d.NewRecord(), d.keys(opts)), opts)...)
+}
+
+// AggColumns returns the flat columns of the Agg entries, the Meta
+// fields followed by the date and the requested keys in request order
+func (d *Dataset) AggColumns(optionsList ...func(*ListOptions)) []string {
+	opts := d.parseOpts(optionsList)
+	keys := d.keys(opts)
+	if len(keys) == 0 {
+		keys = d.AggKeys
+	}
+
+	list := d.metaKeys()
+	list = appendKeys(list, d.groupKeys(opts)...)
+	list = appendKeys(list, "from", "to", "date")
+	for _, key := range keys {
+		if !d.isMetaKey(key) {
+			list = appendKeys(list, key)
+		}
+	}
+	list = withDistance(columns(d.NewSeries(), list), opts)
+	return append(list, computedKeys(d.numericKeys(d.NewSeries(), keys), opts)...)
+}
+
+// SumColumns returns the flat columns of the Sum entries, the Meta
+// fields followed by the SumFields
+func (d *Dataset) SumColumns(optionsList ...func(*ListOptions)) []string {
+	opts := d.parseOpts(optionsList)
+	list := d.metaKeys()
+	list = appendKeys(list, d.groupKeys(opts)...)
+	if opts.Interval != "" && opts.Interval != IntervalDay {
+		list = appendKeys(list, "from", "to")
+	}
+	for _, f := range d.SumFields {
+		list = appendKeys(list, f.Name)
+	}
+	list = withDistance(columns(d.NewTotal(), list), opts)
+	return append(list, computedKeys(d.sumKeys(), opts)...)
+}
+
+// withDistance appends the distance column to near queries, or
+// removes it otherwise
+func withDistance(list []string, opts ListOptions) []string {
+	var cols []string
+	for _, col := range list {
+		if col != "distance" {
+			cols = append(cols, col)
+		}
+	}
+	if len(opts.Near) > 0 {
+		cols = append(cols, "distance")
+	}
+	return cols
+}
+
+// metaKeys returns the names of the Meta fields
+func (d *Dataset) metaKeys() []string {
+	var list []string
+	for _, f := range d.Meta {
+		list = append(list, f.Name)
+	}
+	return list
+}
+
+// Rows flattens a typed entry to rows of the columns, Agg entries are
+// flattened to a row per date. Missing values are left empty, dates
+// are formatted as RFC 3339 and lists are joined with `;`
+func Rows(entry interface{}, columns []string) [][]string {
+	fields := entryValues(entry)
+
+	// series are flattened to a row per date
+	n := 1
+	if date, ok := fields["date"]; ok && date.Kind() == reflect.Slice {
+		n = date.Len()
+	}
+
+	rows := make([][]string, 0, n)
+	for i := 0; i < n; i++ {
+		row := make([]string, len(columns))
+		for j, col := range columns {
+			switch col {
+			case "lon", "lat":
+				v, ok := fields["loc"]
+				if !ok {
+					continue
+				}
+				if loc, ok := v.Interface().(*Point); ok && loc != nil && len(loc.Coordinates) == 2 {
+					if col == "lon" {
+						row[j] = format(loc.Coordinates[0])
+					} else {
+						row[j] = format(loc.Coordinates[1])
+					}
+				}
+			default:
+				v, ok := fields[col]
+				if !ok {
+					continue
+				}
+				if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Ptr {
+					if i >= v.Len() {
+						continue
+					}
+					v = v.Index(i)
+				}
+				row[j] = format(v.Interface())
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// columns returns the json keys of a typed entry in the order of the
+// keys, or in struct field order if none, splitting `loc` to `lon`
+// and `lat`
+func columns(entry interface{}, keys []string) []string {
+	names := fieldNames(reflect.TypeOf(entry))
+	if len(keys) == 0 {
+		keys = names
+	}
+
+	var list []string
+	for _, key := range keys {
+		if !IsValidKey(key, names) {
+			continue
+		}
+		if key == "loc" {
+			list = append(list, "lon", "lat")
+			continue
+		}
+		list = append(list, key)
+	}
+	return list
+}
+
+// fieldNames returns the json keys of a struct type in field order,
+// including the keys of embedded structs
+func fieldNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, fieldNames(f.Type)...)
+			continue
+		}
+		if name := jsonName(f); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// entryValues returns the fields of a typed entry by json key,
+// including the computed keys
+func entryValues(entry interface{}) map[string]reflect.Value {
+	c, ok := entry.(*Computed)
+	if !ok {
+		return fieldValues(reflect.ValueOf(entry))
+	}
+
+	fields := fieldValues(reflect.ValueOf(c.Entry))
+	for key, v := range c.Values {
+		fields[key] = reflect.ValueOf(v)
+	}
+	return fields
+}
+
+// fieldValues returns the fields of a struct value by json key,
+// including the fields of embedded structs
+func fieldValues(v reflect.Value) map[string]reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Anonymous {
+			for name, value := range fieldValues(v.Field(i)) {
+				fields[name] = value
+			}
+			continue
+		}
+		if name := jsonName(f); name != "" {
+			fields[name] = v.Field(i)
+		}
+	}
+	return fields
+}
+
+// jsonName returns the json key of a struct field
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// format formats a value as a csv cell
+func format(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		v = rv.Elem().Interface()
+	}
+
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case []string:
+		return strings.Join(t, ";")
+	case nil:
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// idCacheTTL is the time known ids are cached for
+const idCacheTTL = time.Hour
+
+// InvalidParam describes a request parameter holding invalid values
+type InvalidParam struct {
+	Name   string   `json:"name"`
+	Reason string   `json:"reason"`
+	Values []string `json:"values,omitempty"`
+	Valid  []string `json:"valid,omitempty"`
+}
+
+// ValidationError is returned when list options hold invalid values
+type ValidationError struct {
+	Params []InvalidParam
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	var list []string
+	for _, p := range e.Params {
+		list = append(list, fmt.Sprintf("%s: %s", p.Name, p.Reason))
+	}
+	return "invalid request, " + strings.Join(list, ", ")
+}
+
+// validate checks the list options against the dataset keys and ids
+func (d *Dataset) validate(s store.Store, opts ListOptions) error {
+	var params []InvalidParam
+
+	if !opts.From.IsZero() && !opts.To.IsZero() && opts.From.After(opts.To) {
+		params = append(params, InvalidParam{
+			Name:   "from",
+			Reason: "from date is after to date",
+			Values: []string{opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02")},
+		})
+	}
+
+	params = append(params, d.validateGeo(opts)...)
+
+	if opts.Interval != "" && !IsValidKey(opts.Interval, Intervals) {
+		params = append(params, InvalidParam{
+			Name: "interval", Reason: "unsupported interval", Values: []string{opts.Interval}, Valid: Intervals,
+		})
+	}
+
+	if opts.Fill != "" && !IsValidKey(opts.Fill, Fills) {
+		params = append(params, InvalidParam{
+			Name: "fill", Reason: "unsupported fill method", Values: []string{opts.Fill}, Valid: Fills,
+		})
+	}
+
+	// windows computed over a page would miss the days of the other pages
+	if len(opts.Transforms) > 0 && (opts.Limit > 0 || opts.Offset > 0 || opts.Cursor != "") {
+		params = append(params, InvalidParam{
+			Name: "transform", Reason: "transforms can't be applied to paged lists, request the whole date range or the aggregated data",
+		})
+	}
+
+	if opts.Quality && len(d.Checks) == 0 {
+		params = append(params, InvalidParam{
+			Name: "quality", Reason: "the dataset has no quality checks", Values: []string{"true"},
+		})
+	}
+
+	if _, ok := d.region(opts.Region); opts.Region != "" && !ok {
+		params = append(params, InvalidParam{
+			Name: "region", Reason: "unknown region", Values: []string{opts.Region}, Valid: d.regionNames(),
+		})
+	}
+
+	if _, ok := d.rollup(opts.GroupBy); opts.GroupBy != "" && !ok {
+		reason := "unsupported grouping"
+		if len(d.Rollups) == 0 {
+			reason = "the dataset can't be grouped"
+		}
+		params = append(params, InvalidParam{
+			Name: "group_by", Reason: reason, Values: []string{opts.GroupBy}, Valid: d.rollupNames(),
+		})
+	}
+
+	if opts.Per != "" {
+		switch {
+		case !d.IsValidKey("population"):
+			params = append(params, InvalidParam{
+				Name: "per", Reason: "the dataset has no population", Values: []string{opts.Per},
+			})
+		case !IsValidKey(opts.Per, PerUnits):
+			params = append(params, InvalidParam{
+				Name: "per", Reason: "unsupported per capita unit", Values: []string{opts.Per}, Valid: PerUnits,
+			})
+		}
+	}
+
+	if unknown := d.unknownKeys(opts); len(unknown) > 0 {
+		params = append(params, InvalidParam{
+			Name:   "keys",
+			Reason: "unknown keys",
+			Values: unknown,
+			Valid:  d.validKeys(),
+		})
+	}
+
+	if len(opts.IDs) > 0 || len(opts.Exclude) > 0 {
+		known, err := d.knownIDs(s)
+		if err != nil {
+			return err
+		}
+		for name, list := range map[string][]string{d.Param: opts.IDs, "exclude": opts.Exclude} {
+			var unknown []string
+			for _, id := range ids(list) {
+				if !IsValidKey(id, known) {
+					unknown = append(unknown, id)
+				}
+			}
+			if len(unknown) > 0 {
+				params = append(params, InvalidParam{
+					Name:   name,
+					Reason: "unknown " + d.IDField,
+					Values: unknown,
+					Valid:  known,
+				})
+			}
+		}
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return &ValidationError{Params: params}
+}
+
+// unknownKeys returns the requested keys that can not be requested
+func (d *Dataset) unknownKeys(opts ListOptions) []string {
+	if strings.Contains(opts.Keys, "all") || opts.Keys == "" {
+		return nil
+	}
+
+	var unknown []string
+	for _, key := range strings.Split(opts.Keys, ",") {
+		key = strings.TrimSpace(key)
+		if _, ok := d.metric(key); key != "" && !d.IsValidKey(key) && !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown
+}
+
+// validKeys returns the keys that can be requested, the stored keys
+// followed by the metrics
+func (d *Dataset) validKeys() []string {
+	list := append([]string{}, d.ValidKeys...)
+	for _, m := range d.Metrics {
+		list = append(list, m.Name)
+	}
+	return list
+}
+
+// knownIDs returns the distinct IDField values of the collection,
+// cached for an hour
+func (d *Dataset) knownIDs(s store.Store) ([]string, error) {
+	d.ids.mu.Lock()
+	defer d.ids.mu.Unlock()
+
+	if d.ids.ids != nil && d.ids.store == s && time.Since(d.ids.updatedAt) < idCacheTTL {
+		return d.ids.ids, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := s.Aggregate(ctx, d.Collection, store.Query{}, store.Group{
+		By:     d.IDField,
+		Fields: []Field{{Name: "id", Op: store.First, Key: d.IDField}},
+		Sort:   "id",
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "db.%s.ids()", d.Collection)
+	}
+	defer c.Close(ctx)
+
+	known := []string{}
+	for c.Next(ctx) {
+		var entry struct {
+			ID string `bson:"id"`
+		}
+		if err := c.Decode(&entry); err != nil {
+			return nil, errors.Wrapf(err, "db.%s.ids()", d.Collection)
+		}
+		if entry.ID != "" {
+			known = append(known, strings.ToUpper(entry.ID))
+		}
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrapf(err, "db.%s.ids()", d.Collection)
+	}
+
+	d.ids.store, d.ids.ids, d.ids.updatedAt = s, known, time.Now()
+	return known, nil
+}
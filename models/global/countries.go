@@ -0,0 +1,251 @@
+package global
+
+// Country holds the metadata of a country used to group and filter
+// the global data
+type Country struct {
+	// Continent is the continent of the country, e.g. `Europe`
+	Continent string
+	// WHORegion is the WHO region of the country, e.g. `EURO`
+	WHORegion string
+	// IncomeGroup is the World Bank income group of the country
+	// (FY2021 classification), empty if not classified
+	IncomeGroup string
+	// EU is set for the member states of the European Union
+	EU bool
+}
+
+// countries maps the iso3 codes to the country metadata
+var countries = map[string]Country{
+	"AFG": {"Asia", "EMRO", "Low income", false},
+	"AGO": {"Africa", "AFRO", "Lower middle income", false},
+	"ALB": {"Europe", "EURO", "Upper middle income", false},
+	"AND": {"Europe", "EURO", "High income", false},
+	"ARE": {"Asia", "EMRO", "High income", false},
+	"ARG": {"South America", "AMRO", "Upper middle income", false},
+	"ARM": {"Asia", "EURO", "Upper middle income", false},
+	"ATG": {"North America", "AMRO", "High income", false},
+	"AUS": {"Oceania", "WPRO", "High income", false},
+	"AUT": {"Europe", "EURO", "High income", true},
+	"AZE": {"Asia", "EURO", "Upper middle income", false},
+	"BDI": {"Africa", "AFRO", "Low income", false},
+	"BEL": {"Europe", "EURO", "High income", true},
+	"BEN": {"Africa", "AFRO", "Lower middle income", false},
+	"BFA": {"Africa", "AFRO", "Low income", false},
+	"BGD": {"Asia", "SEARO", "Lower middle income", false},
+	"BGR": {"Europe", "EURO", "Upper middle income", true},
+	"BHR": {"Asia", "EMRO", "High income", false},
+	"BHS": {"North America", "AMRO", "High income", false},
+	"BIH": {"Europe", "EURO", "Upper middle income", false},
+	"BLR": {"Europe", "EURO", "Upper middle income", false},
+	"BLZ": {"North America", "AMRO", "Upper middle income", false},
+	"BOL": {"South America", "AMRO", "Lower middle income", false},
+	"BRA": {"South America", "AMRO", "Upper middle income", false},
+	"BRB": {"North America", "AMRO", "High income", false},
+	"BRN": {"Asia", "WPRO", "High income", false},
+	"BTN": {"Asia", "SEARO", "Lower middle income", false},
+	"BWA": {"Africa", "AFRO", "Upper middle income", false},
+	"CAF": {"Africa", "AFRO", "Low income", false},
+	"CAN": {"North America", "AMRO", "High income", false},
+	"CHE": {"Europe", "EURO", "High income", false},
+	"CHL": {"South America", "AMRO", "High income", false},
+	"CHN": {"Asia", "WPRO", "Upper middle income", false},
+	"CIV": {"Africa", "AFRO", "Lower middle income", false},
+	"CMR": {"Africa", "AFRO", "Lower middle income", false},
+	"COD": {"Africa", "AFRO", "Low income", false},
+	"COG": {"Africa", "AFRO", "Lower middle income", false},
+	"COK": {"Oceania", "WPRO", "", false},
+	"COL": {"South America", "AMRO", "Upper middle income", false},
+	"COM": {"Africa", "AFRO", "Lower middle income", false},
+	"CPV": {"Africa", "AFRO", "Lower middle income", false},
+	"CRI": {"North America", "AMRO", "Upper middle income", false},
+	"CUB": {"North America", "AMRO", "Upper middle income", false},
+	"CYP": {"Asia", "EURO", "High income", true},
+	"CZE": {"Europe", "EURO", "High income", true},
+	"DEU": {"Europe", "EURO", "High income", true},
+	"DJI": {"Africa", "EMRO", "Lower middle income", false},
+	"DMA": {"North America", "AMRO", "Upper middle income", false},
+	"DNK": {"Europe", "EURO", "High income", true},
+	"DOM": {"North America", "AMRO", "Upper middle income", false},
+	"DZA": {"Africa", "AFRO", "Lower middle income", false},
+	"ECU": {"South America", "AMRO", "Upper middle income", false},
+	"EGY": {"Africa", "EMRO", "Lower middle income", false},
+	"ERI": {"Africa", "AFRO", "Low income", false},
+	"ESP": {"Europe", "EURO", "High income", true},
+	"EST": {"Europe", "EURO", "High income", true},
+	"ETH": {"Africa", "AFRO", "Low income", false},
+	"FIN": {"Europe", "EURO", "High income", true},
+	"FJI": {"Oceania", "WPRO", "Upper middle income", false},
+	"FRA": {"Europe", "EURO", "High income", true},
+	"FSM": {"Oceania", "WPRO", "Lower middle income", false},
+	"GAB": {"Africa", "AFRO", "Upper middle income", false},
+	"GBR": {"Europe", "EURO", "High income", false},
+	"GEO": {"Asia", "EURO", "Upper middle income", false},
+	"GHA": {"Africa", "AFRO", "Lower middle income", false},
+	"GIN": {"Africa", "AFRO", "Low income", false},
+	"GMB": {"Africa", "AFRO", "Low income", false},
+	"GNB": {"Africa", "AFRO", "Low income", false},
+	"GNQ": {"Africa", "AFRO", "Upper middle income", false},
+	"GRC": {"Europe", "EURO", "High income", true},
+	"GRD": {"North America", "AMRO", "Upper middle income", false},
+	"GTM": {"North America", "AMRO", "Upper middle income", false},
+	"GUY": {"South America", "AMRO", "Upper middle income", false},
+	"HND": {"North America", "AMRO", "Lower middle income", false},
+	"HRV": {"Europe", "EURO", "High income", true},
+	"HTI": {"North America", "AMRO", "Low income", false},
+	"HUN": {"Europe", "EURO", "High income", true},
+	"IDN": {"Asia", "SEARO", "Upper middle income", false},
+	"IND": {"Asia", "SEARO", "Lower middle income", false},
+	"IRL": {"Europe", "EURO", "High income", true},
+	"IRN": {"Asia", "EMRO", "Upper middle income", false},
+	"IRQ": {"Asia", "EMRO", "Upper middle income", false},
+	"ISL": {"Europe", "EURO", "High income", false},
+	"ISR": {"Asia", "EURO", "High income", false},
+	"ITA": {"Europe", "EURO", "High income", true},
+	"JAM": {"North America", "AMRO", "Upper middle income", false},
+	"JOR": {"Asia", "EMRO", "Upper middle income", false},
+	"JPN": {"Asia", "WPRO", "High income", false},
+	"KAZ": {"Asia", "EURO", "Upper middle income", false},
+	"KEN": {"Africa", "AFRO", "Lower middle income", false},
+	"KGZ": {"Asia", "EURO", "Lower middle income", false},
+	"KHM": {"Asia", "WPRO", "Lower middle income", false},
+	"KIR": {"Oceania", "WPRO", "Lower middle income", false},
+	"KNA": {"North America", "AMRO", "High income", false},
+	"KOR": {"Asia", "WPRO", "High income", false},
+	"KWT": {"Asia", "EMRO", "High income", false},
+	"LAO": {"Asia", "WPRO", "Lower middle income", false},
+	"LBN": {"Asia", "EMRO", "Upper middle income", false},
+	"LBR": {"Africa", "AFRO", "Low income", false},
+	"LBY": {"Africa", "EMRO", "Upper middle income", false},
+	"LCA": {"North America", "AMRO", "Upper middle income", false},
+	"LIE": {"Europe", "EURO", "High income", false},
+	"LKA": {"Asia", "SEARO", "Lower middle income", false},
+	"LSO": {"Africa", "AFRO", "Lower middle income", false},
+	"LTU": {"Europe", "EURO", "High income", true},
+	"LUX": {"Europe", "EURO", "High income", true},
+	"LVA": {"Europe", "EURO", "High income", true},
+	"MAR": {"Africa", "EMRO", "Lower middle income", false},
+	"MCO": {"Europe", "EURO", "High income", false},
+	"MDA": {"Europe", "EURO", "Lower middle income", false},
+	"MDG": {"Africa", "AFRO", "Low income", false},
+	"MDV": {"Asia", "SEARO", "Upper middle income", false},
+	"MEX": {"North America", "AMRO", "Upper middle income", false},
+	"MHL": {"Oceania", "WPRO", "Upper middle income", false},
+	"MKD": {"Europe", "EURO", "Upper middle income", false},
+	"MLI": {"Africa", "AFRO", "Low income", false},
+	"MLT": {"Europe", "EURO", "High income", true},
+	"MMR": {"Asia", "SEARO", "Lower middle income", false},
+	"MNE": {"Europe", "EURO", "Upper middle income", false},
+	"MNG": {"Asia", "WPRO", "Lower middle income", false},
+	"MOZ": {"Africa", "AFRO", "Low income", false},
+	"MRT": {"Africa", "AFRO", "Lower middle income", false},
+	"MUS": {"Africa", "AFRO", "High income", false},
+	"MWI": {"Africa", "AFRO", "Low income", false},
+	"MYS": {"Asia", "WPRO", "Upper middle income", false},
+	"NAM": {"Africa", "AFRO", "Upper middle income", false},
+	"NER": {"Africa", "AFRO", "Low income", false},
+	"NGA": {"Africa", "AFRO", "Lower middle income", false},
+	"NIC": {"North America", "AMRO", "Lower middle income", false},
+	"NIU": {"Oceania", "WPRO", "", false},
+	"NLD": {"Europe", "EURO", "High income", true},
+	"NOR": {"Europe", "EURO", "High income", false},
+	"NPL": {"Asia", "SEARO", "Lower middle income", false},
+	"NRU": {"Oceania", "WPRO", "High income", false},
+	"NZL": {"Oceania", "WPRO", "High income", false},
+	"OMN": {"Asia", "EMRO", "High income", false},
+	"PAK": {"Asia", "EMRO", "Lower middle income", false},
+	"PAN": {"North America", "AMRO", "High income", false},
+	"PER": {"South America", "AMRO", "Upper middle income", false},
+	"PHL": {"Asia", "WPRO", "Lower middle income", false},
+	"PLW": {"Oceania", "WPRO", "High income", false},
+	"PNG": {"Oceania", "WPRO", "Lower middle income", false},
+	"POL": {"Europe", "EURO", "High income", true},
+	"PRK": {"Asia", "SEARO", "Low income", false},
+	"PRT": {"Europe", "EURO", "High income", true},
+	"PRY": {"South America", "AMRO", "Upper middle income", false},
+	"PSE": {"Asia", "EMRO", "Lower middle income", false},
+	"QAT": {"Asia", "EMRO", "High income", false},
+	"ROU": {"Europe", "EURO", "Upper middle income", true},
+	"RUS": {"Europe", "EURO", "Upper middle income", false},
+	"RWA": {"Africa", "AFRO", "Low income", false},
+	"SAU": {"Asia", "EMRO", "High income", false},
+	"SDN": {"Africa", "EMRO", "Low income", false},
+	"SEN": {"Africa", "AFRO", "Lower middle income", false},
+	"SGP": {"Asia", "WPRO", "High income", false},
+	"SLB": {"Oceania", "WPRO", "Lower middle income", false},
+	"SLE": {"Africa", "AFRO", "Low income", false},
+	"SLV": {"North America", "AMRO", "Lower middle income", false},
+	"SMR": {"Europe", "EURO", "High income", false},
+	"SOM": {"Africa", "EMRO", "Low income", false},
+	"SRB": {"Europe", "EURO", "Upper middle income", false},
+	"SSD": {"Africa", "AFRO", "Low income", false},
+	"STP": {"Africa", "AFRO", "Lower middle income", false},
+	"SUR": {"South America", "AMRO", "Upper middle income", false},
+	"SVK": {"Europe", "EURO", "High income", true},
+	"SVN": {"Europe", "EURO", "High income", true},
+	"SWE": {"Europe", "EURO", "High income", true},
+	"SWZ": {"Africa", "AFRO", "Lower middle income", false},
+	"SYC": {"Africa", "AFRO", "High income", false},
+	"SYR": {"Asia", "EMRO", "Low income", false},
+	"TCD": {"Africa", "AFRO", "Low income", false},
+	"TGO": {"Africa", "AFRO", "Low income", false},
+	"THA": {"Asia", "SEARO", "Upper middle income", false},
+	"TJK": {"Asia", "EURO", "Low income", false},
+	"TKM": {"Asia", "EURO", "Upper middle income", false},
+	"TLS": {"Asia", "SEARO", "Lower middle income", false},
+	"TON": {"Oceania", "WPRO", "Upper middle income", false},
+	"TTO": {"North America", "AMRO", "High income", false},
+	"TUN": {"Africa", "EMRO", "Lower middle income", false},
+	"TUR": {"Asia", "EURO", "Upper middle income", false},
+	"TUV": {"Oceania", "WPRO", "Upper middle income", false},
+	"TWN": {"Asia", "WPRO", "High income", false},
+	"TZA": {"Africa", "AFRO", "Lower middle income", false},
+	"UGA": {"Africa", "AFRO", "Low income", false},
+	"UKR": {"Europe", "EURO", "Lower middle income", false},
+	"URY": {"South America", "AMRO", "High income", false},
+	"USA": {"North America", "AMRO", "High income", false},
+	"UZB": {"Asia", "EURO", "Lower middle income", false},
+	"VAT": {"Europe", "EURO", "", false},
+	"VCT": {"North America", "AMRO", "Upper middle income", false},
+	"VEN": {"South America", "AMRO", "Upper middle income", false},
+	"VNM": {"Asia", "WPRO", "Lower middle income", false},
+	"VUT": {"Oceania", "WPRO", "Lower middle income", false},
+	"WSM": {"Oceania", "WPRO", "Upper middle income", false},
+	"XKX": {"Europe", "EURO", "Upper middle income", false},
+	"YEM": {"Asia", "EMRO", "Low income", false},
+	"ZAF": {"Africa", "AFRO", "Upper middle income", false},
+	"ZMB": {"Africa", "AFRO", "Lower middle income", false},
+	"ZWE": {"Africa", "AFRO", "Lower middle income", false},
+}
+
+// regions maps the names of the regions the global data can be filtered
+// by to the iso3 codes of their countries, the EU, the continents, the
+// WHO regions and the income groups
+func regions() map[string][]string {
+	list := make(map[string][]string)
+	for iso3, c := range countries {
+		for _, name := range []string{c.Continent, c.WHORegion, c.IncomeGroup} {
+			if name != "" {
+				list[name] = append(list[name], iso3)
+			}
+		}
+		if c.EU {
+			list["EU"] = append(list["EU"], iso3)
+		}
+	}
+	return list
+}
+
+// continentOf returns the continent of a country
+func continentOf(iso3 string) string {
+	return countries[iso3].Continent
+}
+
+// whoRegionOf returns the WHO region of a country
+func whoRegionOf(iso3 string) string {
+	return countries[iso3].WHORegion
+}
+
+// incomeGroupOf returns the income group of a country
+func incomeGroupOf(iso3 string) string {
+	return countries[iso3].IncomeGroup
+}
@@ -2,6 +2,8 @@ package global
 
 import (
 	"time"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
 )
 
 var (
@@ -29,71 +31,219 @@ var (
 	}
 )
 
-// ListOptions represents the filter structure to query
-// the database
-type ListOptions struct {
-	Limit int
-	ISO3  string
-	Keys  string
-	From  time.Time
-	To    time.Time
-	Key   string
-}
+// Dataset describes the global (country level) collection
+var Dataset = &dataset.Dataset{
+	Name:       "global",
+	Collection: "global",
+	Path:       "/global",
+	Param:      "country",
+	IDField:    "iso3",
+	GroupBy:    "uid",
+	GeoField:   "loc",
+	ValidKeys:  validKeys,
+	NewRecord:  func() interface{} { return new(Record) },
+	NewSeries:  func() interface{} { return new(Series) },
+	NewTotal:   func() interface{} { return new(Total) },
+	Meta: []dataset.Field{
+		{Name: "uid", Op: "$first", Key: "uid"},
+		{Name: "iso2", Op: "$first", Key: "iso2"},
+		{Name: "iso3", Op: "$first", Key: "iso3"},
+		{Name: "loc", Op: "$first", Key: "loc"},
+		{Name: "country", Op: "$first", Key: "country"},
+		{Name: "sources", Op: "$addToSet", Key: "source"},
+		{Name: "population", Op: "$first", Key: "population"},
+		{Name: "last_updated_at", Op: "$last", Key: "last_updated_at"},
+	},
+	AggKeys: []string{
+		"new_cases", "new_deaths", "cases", "deaths",
+		"recovered", "active", "critical",
+	},
+	SumFields: []dataset.Field{
+		{Name: "total_cases", Op: "$last", Key: "cases"},
+		{Name: "total_deaths", Op: "$last", Key: "deaths"},
+		{Name: "total_recovered", Op: "$last", Key: "recovered"},
+		{Name: "total_active", Op: "$last", Key: "active"},
+		{Name: "total_critical", Op: "$last", Key: "critical"},
+		{Name: "total_tests", Op: "$last", Key: "tests"},
+		{Name: "total_hospital_admissions", Op: "$last", Key: "hospital_admissions"},
+		{Name: "total_hospital_discharges", Op: "$last", Key: "hospital_discharges"},
+		{Name: "total_intubated_unvac", Op: "$last", Key: "intubated_unvac"},
+		{Name: "total_intubated_vac", Op: "$last", Key: "intubated_vac"},
 
-// NewListOpts create a new ListOptions struct
-func NewListOpts() []func(*ListOptions) {
-	return make([]func(*ListOptions), 0)
+		{Name: "cases", Op: "$sum", Key: "new_cases"},
+		{Name: "deaths", Op: "$sum", Key: "new_deaths"},
+		{Name: "recovered", Op: "$sum", Key: "new_recovered"},
+		{Name: "tests", Op: "$sum", Key: "new_tests"},
+		{Name: "tests_rtpcr", Op: "$sum", Key: "new_tests_rtpcr"},
+		{Name: "tests_rapid", Op: "$sum", Key: "new_tests_rapid"},
+	},
+	Metrics: []dataset.Metric{
+		dataset.Ratio("positivity_rate", "new_cases", "new_tests", 100),
+		dataset.RollingRatio("positivity_rate_7d", "new_cases", "new_tests", 100, 7),
+		dataset.Rate("tests_per_1k", "new_tests", 1000),
+		dataset.Rate("total_tests_per_1k", "tests", 1000),
+		dataset.Ratio("rtpcr_share", "new_tests_rtpcr", "new_tests", 100),
+		dataset.Ratio("rapid_share", "new_tests_rapid", "new_tests", 100),
+	},
+	Trends: []dataset.Trend{
+		{Key: "new_cases"},
+		{Key: "new_deaths"},
+		{Key: "cases", Cumulative: true},
+	},
+	Incidence: "new_cases",
+	Regions:   regions(),
+	Rollups: []dataset.Rollup{
+		{Name: "continent", Key: "group", Lookup: continentOf},
+		{Name: "who_region", Key: "group", Lookup: whoRegionOf},
+		{Name: "income_group", Key: "group", Lookup: incomeGroupOf},
+	},
+	Weights: map[string]string{
+		"incidence_rate":      "population",
+		"case_fatality_ratio": "cases",
+	},
+	Units: map[string]string{
+		"incidence_rate":      "per " + dataset.Per100k,
+		"case_fatality_ratio": dataset.UnitPercent,
+	},
+	Checks: []dataset.Check{
+		{Key: "new_cases"},
+		{Key: "new_deaths"},
+		{Key: "new_tests"},
+		{Key: "cases", Cumulative: true},
+		{Key: "deaths", Cumulative: true},
+		{Key: "tests", Cumulative: true},
+	},
 }
 
-// Limit sets the limit
-func Limit(i int) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Limit = i
-	}
-}
+// Record represents a single document of the global collection,
+// keys not requested are left empty
+type Record struct {
+	Date          *time.Time     `bson:"date,omitempty" json:"date,omitempty"`
+	UID           *int64         `bson:"uid,omitempty" json:"uid,omitempty"`
+	Country       *string        `bson:"country,omitempty" json:"country,omitempty"`
+	ISO2          *string        `bson:"iso2,omitempty" json:"iso2,omitempty"`
+	ISO3          *string        `bson:"iso3,omitempty" json:"iso3,omitempty"`
+	Loc           *dataset.Point `bson:"loc,omitempty" json:"loc,omitempty"`
+	Population    *int64         `bson:"population,omitempty" json:"population,omitempty"`
+	Source        *string        `bson:"source,omitempty" json:"source,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at,omitempty" json:"last_updated_at,omitempty"`
+	Distance      *float64       `bson:"-" json:"distance,omitempty"`
 
-// ISO3 sets the iso3 country code
-func ISO3(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.ISO3 = i
-	}
-}
+	Cases             *int64   `bson:"cases,omitempty" json:"cases,omitempty"`
+	Deaths            *int64   `bson:"deaths,omitempty" json:"deaths,omitempty"`
+	Recovered         *int64   `bson:"recovered,omitempty" json:"recovered,omitempty"`
+	Active            *int64   `bson:"active,omitempty" json:"active,omitempty"`
+	Critical          *int64   `bson:"critical,omitempty" json:"critical,omitempty"`
+	NewCases          *int64   `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewDeaths         *int64   `bson:"new_deaths,omitempty" json:"new_deaths,omitempty"`
+	NewRecovered      *int64   `bson:"new_recovered,omitempty" json:"new_recovered,omitempty"`
+	CaseFatalityRatio *float64 `bson:"case_fatality_ratio,omitempty" json:"case_fatality_ratio,omitempty"`
+	IncidenceRate     *float64 `bson:"incidence_rate,omitempty" json:"incidence_rate,omitempty"`
 
-// Keys sets the keys to return
-func Keys(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Keys = i
-	}
-}
+	Tests         *int64 `bson:"tests,omitempty" json:"tests,omitempty"`
+	NewTests      *int64 `bson:"new_tests,omitempty" json:"new_tests,omitempty"`
+	TestsRTPCR    *int64 `bson:"tests_rtpcr,omitempty" json:"tests_rtpcr,omitempty"`
+	NewTestsRTPCR *int64 `bson:"new_tests_rtpcr,omitempty" json:"new_tests_rtpcr,omitempty"`
+	TestsRapid    *int64 `bson:"tests_rapid,omitempty" json:"tests_rapid,omitempty"`
+	NewTestsRapid *int64 `bson:"new_tests_rapid,omitempty" json:"new_tests_rapid,omitempty"`
 
-// From sets the start date to retrieve data from
-func From(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.From = i
-	}
+	ICUDischarges         *int64 `bson:"icu_discharges,omitempty" json:"icu_discharges,omitempty"`
+	HospitalAdmissions    *int64 `bson:"hospital_admissions,omitempty" json:"hospital_admissions,omitempty"`
+	HospitalDischarges    *int64 `bson:"hospital_discharges,omitempty" json:"hospital_discharges,omitempty"`
+	NewHospitalAdmissions *int64 `bson:"new_hospital_admissions,omitempty" json:"new_hospital_admissions,omitempty"`
+	NewHospitalDischarges *int64 `bson:"new_hospital_discharges,omitempty" json:"new_hospital_discharges,omitempty"`
+	IntubatedUnvac        *int64 `bson:"intubated_unvac,omitempty" json:"intubated_unvac,omitempty"`
+	IntubatedVac          *int64 `bson:"intubated_vac,omitempty" json:"intubated_vac,omitempty"`
+
+	ICUOccupancy    *float64 `bson:"icu_occupancy,omitempty" json:"icu_occupancy,omitempty"`
+	BedsOccupancy   *float64 `bson:"beds_occupancy,omitempty" json:"beds_occupancy,omitempty"`
+	ICUAvailability *float64 `bson:"icu_availability,omitempty" json:"icu_availability,omitempty"`
 }
 
-// To sets the end date to retrieve data from
-func To(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.To = i
-	}
+// Meta represents the fields describing a country in Agg and Sum
+type Meta struct {
+	UID           *int64         `bson:"uid" json:"uid"`
+	ISO2          *string        `bson:"iso2" json:"iso2"`
+	ISO3          *string        `bson:"iso3" json:"iso3"`
+	Loc           *dataset.Point `bson:"loc" json:"loc"`
+	Country       *string        `bson:"country" json:"country"`
+	Group         *string        `bson:"-" json:"group,omitempty"`
+	Sources       []string       `bson:"sources" json:"sources"`
+	Population    *int64         `bson:"population" json:"population"`
+	From          *time.Time     `bson:"from,omitempty" json:"from,omitempty"`
+	To            *time.Time     `bson:"to,omitempty" json:"to,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at" json:"last_updated_at"`
+	Distance      *float64       `bson:"-" json:"distance,omitempty"`
 }
 
-// DefaultOpts sets the defaults
-func DefaultOpts() ListOptions {
-	l := ListOptions{}
-	l.Limit = -1
-	l.ISO3 = ""
-	return l
+// Series represents the daily values of a country pushed in Agg,
+// keys not requested are left empty
+type Series struct {
+	Meta `bson:",inline"`
+
+	Date              []*time.Time `bson:"date,omitempty" json:"date,omitempty"`
+	Cases             []*int64     `bson:"cases,omitempty" json:"cases,omitempty"`
+	Deaths            []*int64     `bson:"deaths,omitempty" json:"deaths,omitempty"`
+	Recovered         []*int64     `bson:"recovered,omitempty" json:"recovered,omitempty"`
+	Active            []*int64     `bson:"active,omitempty" json:"active,omitempty"`
+	Critical          []*int64     `bson:"critical,omitempty" json:"critical,omitempty"`
+	NewCases          []*int64     `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewDeaths         []*int64     `bson:"new_deaths,omitempty" json:"new_deaths,omitempty"`
+	NewRecovered      []*int64     `bson:"new_recovered,omitempty" json:"new_recovered,omitempty"`
+	CaseFatalityRatio []*float64   `bson:"case_fatality_ratio,omitempty" json:"case_fatality_ratio,omitempty"`
+	IncidenceRate     []*float64   `bson:"incidence_rate,omitempty" json:"incidence_rate,omitempty"`
+
+	Tests         []*int64 `bson:"tests,omitempty" json:"tests,omitempty"`
+	NewTests      []*int64 `bson:"new_tests,omitempty" json:"new_tests,omitempty"`
+	TestsRTPCR    []*int64 `bson:"tests_rtpcr,omitempty" json:"tests_rtpcr,omitempty"`
+	NewTestsRTPCR []*int64 `bson:"new_tests_rtpcr,omitempty" json:"new_tests_rtpcr,omitempty"`
+	TestsRapid    []*int64 `bson:"tests_rapid,omitempty" json:"tests_rapid,omitempty"`
+	NewTestsRapid []*int64 `bson:"new_tests_rapid,omitempty" json:"new_tests_rapid,omitempty"`
+
+	ICUDischarges         []*int64 `bson:"icu_discharges,omitempty" json:"icu_discharges,omitempty"`
+	HospitalAdmissions    []*int64 `bson:"hospital_admissions,omitempty" json:"hospital_admissions,omitempty"`
+	HospitalDischarges    []*int64 `bson:"hospital_discharges,omitempty" json:"hospital_discharges,omitempty"`
+	NewHospitalAdmissions []*int64 `bson:"new_hospital_admissions,omitempty" json:"new_hospital_admissions,omitempty"`
+	NewHospitalDischarges []*int64 `bson:"new_hospital_discharges,omitempty" json:"new_hospital_discharges,omitempty"`
+	IntubatedUnvac        []*int64 `bson:"intubated_unvac,omitempty" json:"intubated_unvac,omitempty"`
+	IntubatedVac          []*int64 `bson:"intubated_vac,omitempty" json:"intubated_vac,omitempty"`
+
+	ICUOccupancy    []*float64 `bson:"icu_occupancy,omitempty" json:"icu_occupancy,omitempty"`
+	BedsOccupancy   []*float64 `bson:"beds_occupancy,omitempty" json:"beds_occupancy,omitempty"`
+	ICUAvailability []*float64 `bson:"icu_availability,omitempty" json:"icu_availability,omitempty"`
 }
 
-// IsValidKey checks if a string is in an array
-func IsValidKey(str string, list []string) bool {
-	for _, s := range list {
-		if s == str {
-			return true
-		}
-	}
-	return false
+// Total represents the totals of a country computed in Sum
+type Total struct {
+	Meta `bson:",inline"`
+
+	TotalCases              *int64 `bson:"total_cases" json:"total_cases"`
+	TotalDeaths             *int64 `bson:"total_deaths" json:"total_deaths"`
+	TotalRecovered          *int64 `bson:"total_recovered" json:"total_recovered"`
+	TotalActive             *int64 `bson:"total_active" json:"total_active"`
+	TotalCritical           *int64 `bson:"total_critical" json:"total_critical"`
+	TotalTests              *int64 `bson:"total_tests" json:"total_tests"`
+	TotalHospitalAdmissions *int64 `bson:"total_hospital_admissions" json:"total_hospital_admissions"`
+	TotalHospitalDischarges *int64 `bson:"total_hospital_discharges" json:"total_hospital_discharges"`
+	TotalIntubatedUnvac     *int64 `bson:"total_intubated_unvac" json:"total_intubated_unvac"`
+	TotalIntubatedVac       *int64 `bson:"total_intubated_vac" json:"total_intubated_vac"`
+
+	Cases      *int64 `bson:"cases" json:"cases"`
+	Deaths     *int64 `bson:"deaths" json:"deaths"`
+	Recovered  *int64 `bson:"recovered" json:"recovered"`
+	Tests      *int64 `bson:"tests" json:"tests"`
+	TestsRTPCR *int64 `bson:"tests_rtpcr" json:"tests_rtpcr"`
+	TestsRapid *int64 `bson:"tests_rapid" json:"tests_rapid"`
 }
+
+// Location implements dataset.Located
+func (r *Record) Location() *dataset.Point { return r.Loc }
+
+// SetDistance implements dataset.Located
+func (r *Record) SetDistance(km float64) { r.Distance = &km }
+
+// Location implements dataset.Located
+func (m *Meta) Location() *dataset.Point { return m.Loc }
+
+// SetDistance implements dataset.Located
+func (m *Meta) SetDistance(km float64) { m.Distance = &km }
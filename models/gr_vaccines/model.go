@@ -2,6 +2,8 @@ package gr_vaccines
 
 import (
 	"time"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
 )
 
 var (
@@ -11,75 +13,157 @@ var (
 		"total_distinct_persons", "total_vaccinations", "day_total", "day_diff",
 		"daily_dose_1", "daily_dose_2", "daily_dose_3",
 		"total_dose_1", "total_dose_2", "total_dose_3",
+		"new_total_distinct_persons", "new_total_vaccinations",
 	}
 )
 
-// ListOptions represents the filter structure to query
-// the database
-type ListOptions struct {
-	Limit int
-	UID   string
-	Keys  string
-	From  time.Time
-	To    time.Time
-	Key   string
-}
+// Dataset describes the gr_vaccines (regional unit level) collection
+var Dataset = &dataset.Dataset{
+	Name:        "gr_vaccines",
+	Collection:  "gr_vaccines",
+	Path:        "/vaccines/greece",
+	Param:       "region",
+	IDField:     "uid",
+	GroupBy:     "uid",
+	ValidKeys:   validKeys,
+	NewRecord:   func() interface{} { return new(Record) },
+	NewSeries:   func() interface{} { return new(Series) },
+	NewTotal:    func() interface{} { return new(Total) },
+	DefaultFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	Meta: []dataset.Field{
+		{Name: "uid", Op: "$first", Key: "uid"},
+		{Name: "geo_unit", Op: "$first", Key: "geo_unit"},
+		{Name: "state", Op: "$first", Key: "state"},
+		{Name: "loc", Op: "$first", Key: "loc"},
+		{Name: "region", Op: "$first", Key: "region"},
+		{Name: "sources", Op: "$addToSet", Key: "source"},
+		{Name: "population", Op: "$first", Key: "population"},
+		{Name: "last_updated_at", Op: "$last", Key: "last_updated_at"},
+	},
+	AggKeys: []string{
+		"day_diff", "day_total",
+		"total_distinct_persons", "total_vaccinations",
+		"new_total_distinct_persons", "new_total_vaccinations",
+		"total_dose_1", "total_dose_2", "total_dose_3",
+		"daily_dose_1", "daily_dose_2", "daily_dose_3",
+	},
+	SumFields: []dataset.Field{
+		{Name: "from", Op: "$first", Key: "date"},
+		{Name: "to", Op: "$last", Key: "date"},
 
-// NewListOpts create a new ListOptions struct
-func NewListOpts() []func(*ListOptions) {
-	return make([]func(*ListOptions), 0)
-}
+		{Name: "total_distinct_persons", Op: "$last", Key: "total_distinct_persons"},
+		{Name: "total_vaccinations", Op: "$last", Key: "total_vaccinations"},
 
-// Limit sets the limit
-func Limit(i int) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Limit = i
-	}
-}
+		{Name: "total_dose_1", Op: "$last", Key: "total_dose_1"},
+		{Name: "total_dose_2", Op: "$last", Key: "total_dose_2"},
+		{Name: "total_dose_3", Op: "$last", Key: "total_dose_3"},
 
-// UID sets the uid country code
-func UID(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.UID = i
-	}
-}
+		{Name: "day_diff", Op: "$sum", Key: "day_diff"},
+		{Name: "day_total", Op: "$sum", Key: "day_total"},
 
-// Keys sets the keys to return
-func Keys(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Keys = i
-	}
+		{Name: "daily_dose_1", Op: "$sum", Key: "daily_dose_1"},
+		{Name: "daily_dose_2", Op: "$sum", Key: "daily_dose_2"},
+		{Name: "daily_dose_3", Op: "$sum", Key: "daily_dose_3"},
+
+		{Name: "new_total_distinct_persons", Op: "$sum", Key: "new_total_distinct_persons"},
+		{Name: "new_total_vaccinations", Op: "$sum", Key: "new_total_vaccinations"},
+	},
+	Metrics: []dataset.Metric{
+		dataset.Rate("coverage_dose_1", "total_dose_1", 100),
+		dataset.Rate("coverage_full", "total_dose_2", 100),
+		dataset.Rate("coverage_booster", "total_dose_3", 100),
+		dataset.Ratio("dose_2_completion", "total_dose_2", "total_dose_1", 100),
+		dataset.Ratio("booster_uptake", "total_dose_3", "total_dose_2", 100),
+	},
+	Checks: []dataset.Check{
+		{Key: "daily_dose_1"},
+		{Key: "daily_dose_2"},
+		{Key: "daily_dose_3"},
+		{Key: "total_dose_1", Cumulative: true},
+		{Key: "total_dose_2", Cumulative: true},
+		{Key: "total_dose_3", Cumulative: true},
+		{Key: "total_vaccinations", Cumulative: true},
+	},
 }
 
-// From sets the start date to retrieve data from
-func From(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.From = i
-	}
+// Record represents a single document of the gr_vaccines collection,
+// keys not requested are left empty
+type Record struct {
+	Date          *time.Time     `bson:"date,omitempty" json:"date,omitempty"`
+	UID           *string        `bson:"uid,omitempty" json:"uid,omitempty"`
+	Area          *string        `bson:"area,omitempty" json:"area,omitempty"`
+	AreaID        *int64         `bson:"areaid,omitempty" json:"areaid,omitempty"`
+	GeoUnit       *string        `bson:"geo_unit,omitempty" json:"geo_unit,omitempty"`
+	State         *string        `bson:"state,omitempty" json:"state,omitempty"`
+	Region        *string        `bson:"region,omitempty" json:"region,omitempty"`
+	Loc           *dataset.Point `bson:"loc,omitempty" json:"loc,omitempty"`
+	Population    *int64         `bson:"population,omitempty" json:"population,omitempty"`
+	Source        *string        `bson:"source,omitempty" json:"source,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at,omitempty" json:"last_updated_at,omitempty"`
+
+	TotalDistinctPersons    *int64 `bson:"total_distinct_persons,omitempty" json:"total_distinct_persons,omitempty"`
+	TotalVaccinations       *int64 `bson:"total_vaccinations,omitempty" json:"total_vaccinations,omitempty"`
+	NewTotalDistinctPersons *int64 `bson:"new_total_distinct_persons,omitempty" json:"new_total_distinct_persons,omitempty"`
+	NewTotalVaccinations    *int64 `bson:"new_total_vaccinations,omitempty" json:"new_total_vaccinations,omitempty"`
+	DayTotal                *int64 `bson:"day_total,omitempty" json:"day_total,omitempty"`
+	DayDiff                 *int64 `bson:"day_diff,omitempty" json:"day_diff,omitempty"`
+	DailyDose1              *int64 `bson:"daily_dose_1,omitempty" json:"daily_dose_1,omitempty"`
+	DailyDose2              *int64 `bson:"daily_dose_2,omitempty" json:"daily_dose_2,omitempty"`
+	DailyDose3              *int64 `bson:"daily_dose_3,omitempty" json:"daily_dose_3,omitempty"`
+	TotalDose1              *int64 `bson:"total_dose_1,omitempty" json:"total_dose_1,omitempty"`
+	TotalDose2              *int64 `bson:"total_dose_2,omitempty" json:"total_dose_2,omitempty"`
+	TotalDose3              *int64 `bson:"total_dose_3,omitempty" json:"total_dose_3,omitempty"`
 }
 
-// To sets the end date to retrieve data from
-func To(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.To = i
-	}
+// Meta represents the fields describing a region in Agg and Sum
+type Meta struct {
+	UID           *string        `bson:"uid" json:"uid"`
+	GeoUnit       *string        `bson:"geo_unit" json:"geo_unit"`
+	State         *string        `bson:"state" json:"state"`
+	Loc           *dataset.Point `bson:"loc" json:"loc"`
+	Region        *string        `bson:"region" json:"region"`
+	Sources       []string       `bson:"sources" json:"sources"`
+	Population    *int64         `bson:"population" json:"population"`
+	From          *time.Time     `bson:"from,omitempty" json:"from,omitempty"`
+	To            *time.Time     `bson:"to,omitempty" json:"to,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at" json:"last_updated_at"`
 }
 
-// DefaultOpts sets the defaults
-func DefaultOpts() ListOptions {
-	l := ListOptions{}
-	l.Limit = -1
-	l.From, _ = time.Parse("2006-01-02", "2020-01-01")
-	l.To = time.Now()
-	return l
+// Series represents the daily values of a region pushed in Agg,
+// keys not requested are left empty
+type Series struct {
+	Meta `bson:",inline"`
+
+	Date                    []*time.Time `bson:"date,omitempty" json:"date,omitempty"`
+	TotalDistinctPersons    []*int64     `bson:"total_distinct_persons,omitempty" json:"total_distinct_persons,omitempty"`
+	TotalVaccinations       []*int64     `bson:"total_vaccinations,omitempty" json:"total_vaccinations,omitempty"`
+	NewTotalDistinctPersons []*int64     `bson:"new_total_distinct_persons,omitempty" json:"new_total_distinct_persons,omitempty"`
+	NewTotalVaccinations    []*int64     `bson:"new_total_vaccinations,omitempty" json:"new_total_vaccinations,omitempty"`
+	DayTotal                []*int64     `bson:"day_total,omitempty" json:"day_total,omitempty"`
+	DayDiff                 []*int64     `bson:"day_diff,omitempty" json:"day_diff,omitempty"`
+	DailyDose1              []*int64     `bson:"daily_dose_1,omitempty" json:"daily_dose_1,omitempty"`
+	DailyDose2              []*int64     `bson:"daily_dose_2,omitempty" json:"daily_dose_2,omitempty"`
+	DailyDose3              []*int64     `bson:"daily_dose_3,omitempty" json:"daily_dose_3,omitempty"`
+	TotalDose1              []*int64     `bson:"total_dose_1,omitempty" json:"total_dose_1,omitempty"`
+	TotalDose2              []*int64     `bson:"total_dose_2,omitempty" json:"total_dose_2,omitempty"`
+	TotalDose3              []*int64     `bson:"total_dose_3,omitempty" json:"total_dose_3,omitempty"`
 }
 
-// IsValidKey checks if a string is in an array
-func IsValidKey(str string, list []string) bool {
-	for _, s := range list {
-		if s == str {
-			return true
-		}
-	}
-	return false
+// Total represents the totals of a region computed in Sum
+type Total struct {
+	Meta `bson:",inline"`
+
+	TotalDistinctPersons *int64 `bson:"total_distinct_persons" json:"total_distinct_persons"`
+	TotalVaccinations    *int64 `bson:"total_vaccinations" json:"total_vaccinations"`
+	TotalDose1           *int64 `bson:"total_dose_1" json:"total_dose_1"`
+	TotalDose2           *int64 `bson:"total_dose_2" json:"total_dose_2"`
+	TotalDose3           *int64 `bson:"total_dose_3" json:"total_dose_3"`
+
+	DayDiff                 *int64 `bson:"day_diff" json:"day_diff"`
+	DayTotal                *int64 `bson:"day_total" json:"day_total"`
+	DailyDose1              *int64 `bson:"daily_dose_1" json:"daily_dose_1"`
+	DailyDose2              *int64 `bson:"daily_dose_2" json:"daily_dose_2"`
+	DailyDose3              *int64 `bson:"daily_dose_3" json:"daily_dose_3"`
+	NewTotalDistinctPersons *int64 `bson:"new_total_distinct_persons" json:"new_total_distinct_persons"`
+	NewTotalVaccinations    *int64 `bson:"new_total_vaccinations" json:"new_total_vaccinations"`
 }
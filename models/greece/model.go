@@ -2,6 +2,8 @@ package greece
 
 import (
 	"time"
+
+	"github.com/cvcio/covid-19-api/models/dataset"
 )
 
 var (
@@ -14,70 +16,159 @@ var (
 	}
 )
 
-// ListOptions represents the filter structure to query
-// the database
-type ListOptions struct {
-	Limit int
-	UID   string
-	Keys  string
-	From  time.Time
-	To    time.Time
-	Key   string
-}
+// Dataset describes the greece (regional unit level) collection
+var Dataset = &dataset.Dataset{
+	Name:       "greece",
+	Collection: "greece",
+	Path:       "/greece",
+	Param:      "region",
+	IDField:    "uid",
+	GroupBy:    "uid",
+	GeoField:   "loc",
+	ValidKeys:  validKeys,
+	NewRecord:  func() interface{} { return new(Record) },
+	NewSeries:  func() interface{} { return new(Series) },
+	NewTotal:   func() interface{} { return new(Total) },
+	Meta: []dataset.Field{
+		{Name: "uid", Op: "$first", Key: "uid"},
+		{Name: "geo_unit", Op: "$first", Key: "geo_unit"},
+		{Name: "state", Op: "$first", Key: "state"},
+		{Name: "loc", Op: "$first", Key: "loc"},
+		{Name: "region", Op: "$first", Key: "region"},
+		{Name: "sources", Op: "$addToSet", Key: "source"},
+		{Name: "population", Op: "$first", Key: "population"},
+		{Name: "last_updated_at", Op: "$last", Key: "last_updated_at"},
+	},
+	AggKeys: []string{
+		"new_cases", "new_deaths", "cases", "deaths",
+		"recovered", "active", "critical",
+	},
+	SumFields: []dataset.Field{
+		{Name: "total_cases", Op: "$last", Key: "cases"},
+		{Name: "total_deaths", Op: "$last", Key: "deaths"},
+		{Name: "total_recovered", Op: "$last", Key: "recovered"},
+		{Name: "total_active", Op: "$last", Key: "active"},
+		{Name: "total_critical", Op: "$last", Key: "critical"},
 
-// NewListOpts create a new ListOptions struct
-func NewListOpts() []func(*ListOptions) {
-	return make([]func(*ListOptions), 0)
+		{Name: "cases", Op: "$sum", Key: "new_cases"},
+		{Name: "deaths", Op: "$sum", Key: "new_deaths"},
+		{Name: "recovered", Op: "$sum", Key: "new_recovered"},
+	},
+	Trends: []dataset.Trend{
+		{Key: "new_cases"},
+		{Key: "new_deaths"},
+		{Key: "cases", Cumulative: true},
+	},
+	Incidence: "new_cases",
+	Rollups: []dataset.Rollup{
+		{Name: "state", Key: "state", Keep: []string{"state", "geo_unit"}},
+		{Name: "geo_unit", Key: "geo_unit", Keep: []string{"geo_unit"}},
+		{Name: "country", ID: "EL"},
+	},
+	Weights: map[string]string{
+		"incidence_rate":      "population",
+		"case_fatality_ratio": "cases",
+	},
+	Units: map[string]string{
+		"incidence_rate":      "per " + dataset.Per100k,
+		"case_fatality_ratio": dataset.UnitPercent,
+	},
+	Checks: []dataset.Check{
+		{Key: "new_cases"},
+		{Key: "new_deaths"},
+		{Key: "cases", Cumulative: true},
+		{Key: "deaths", Cumulative: true},
+	},
+	Metrics: []dataset.Metric{
+		dataset.RollingRate("incidence_7d", "new_cases", 100000, 7),
+	},
+	Joins: []*dataset.Join{Vaccines},
 }
 
-// Limit sets the limit
-func Limit(i int) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Limit = i
-	}
-}
+// Record represents a single document of the greece collection,
+// keys not requested are left empty
+type Record struct {
+	Date          *time.Time     `bson:"date,omitempty" json:"date,omitempty"`
+	UID           *string        `bson:"uid,omitempty" json:"uid,omitempty"`
+	GeoUnit       *string        `bson:"geo_unit,omitempty" json:"geo_unit,omitempty"`
+	State         *string        `bson:"state,omitempty" json:"state,omitempty"`
+	Region        *string        `bson:"region,omitempty" json:"region,omitempty"`
+	Loc           *dataset.Point `bson:"loc,omitempty" json:"loc,omitempty"`
+	Population    *int64         `bson:"population,omitempty" json:"population,omitempty"`
+	Source        *string        `bson:"source,omitempty" json:"source,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at,omitempty" json:"last_updated_at,omitempty"`
+	Distance      *float64       `bson:"-" json:"distance,omitempty"`
 
-// UID sets the uid country code
-func UID(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.UID = i
-	}
+	Cases             *int64   `bson:"cases,omitempty" json:"cases,omitempty"`
+	Deaths            *int64   `bson:"deaths,omitempty" json:"deaths,omitempty"`
+	Recovered         *int64   `bson:"recovered,omitempty" json:"recovered,omitempty"`
+	Active            *int64   `bson:"active,omitempty" json:"active,omitempty"`
+	Critical          *int64   `bson:"critical,omitempty" json:"critical,omitempty"`
+	Tests             *int64   `bson:"tests,omitempty" json:"tests,omitempty"`
+	NewCases          *int64   `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewDeaths         *int64   `bson:"new_deaths,omitempty" json:"new_deaths,omitempty"`
+	NewRecovered      *int64   `bson:"new_recovered,omitempty" json:"new_recovered,omitempty"`
+	CaseFatalityRatio *float64 `bson:"case_fatality_ratio,omitempty" json:"case_fatality_ratio,omitempty"`
+	IncidenceRate     *float64 `bson:"incidence_rate,omitempty" json:"incidence_rate,omitempty"`
 }
 
-// Keys sets the keys to return
-func Keys(i string) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.Keys = i
-	}
+// Meta represents the fields describing a region in Agg and Sum
+type Meta struct {
+	UID           *string        `bson:"uid" json:"uid"`
+	GeoUnit       *string        `bson:"geo_unit" json:"geo_unit"`
+	State         *string        `bson:"state" json:"state"`
+	Loc           *dataset.Point `bson:"loc" json:"loc"`
+	Region        *string        `bson:"region" json:"region"`
+	Sources       []string       `bson:"sources" json:"sources"`
+	Population    *int64         `bson:"population" json:"population"`
+	From          *time.Time     `bson:"from,omitempty" json:"from,omitempty"`
+	To            *time.Time     `bson:"to,omitempty" json:"to,omitempty"`
+	LastUpdatedAt *time.Time     `bson:"last_updated_at" json:"last_updated_at"`
+	Distance      *float64       `bson:"-" json:"distance,omitempty"`
 }
 
-// From sets the start date to retrieve data from
-func From(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.From = i
-	}
-}
+// Series represents the daily values of a region pushed in Agg,
+// keys not requested are left empty
+type Series struct {
+	Meta `bson:",inline"`
 
-// To sets the end date to retrieve data from
-func To(i time.Time) func(*ListOptions) {
-	return func(l *ListOptions) {
-		l.To = i
-	}
+	Date              []*time.Time `bson:"date,omitempty" json:"date,omitempty"`
+	Cases             []*int64     `bson:"cases,omitempty" json:"cases,omitempty"`
+	Deaths            []*int64     `bson:"deaths,omitempty" json:"deaths,omitempty"`
+	Recovered         []*int64     `bson:"recovered,omitempty" json:"recovered,omitempty"`
+	Active            []*int64     `bson:"active,omitempty" json:"active,omitempty"`
+	Critical          []*int64     `bson:"critical,omitempty" json:"critical,omitempty"`
+	Tests             []*int64     `bson:"tests,omitempty" json:"tests,omitempty"`
+	NewCases          []*int64     `bson:"new_cases,omitempty" json:"new_cases,omitempty"`
+	NewDeaths         []*int64     `bson:"new_deaths,omitempty" json:"new_deaths,omitempty"`
+	NewRecovered      []*int64     `bson:"new_recovered,omitempty" json:"new_recovered,omitempty"`
+	CaseFatalityRatio []*float64   `bson:"case_fatality_ratio,omitempty" json:"case_fatality_ratio,omitempty"`
+	IncidenceRate     []*float64   `bson:"incidence_rate,omitempty" json:"incidence_rate,omitempty"`
 }
 
-// DefaultOpts sets the defaults
-func DefaultOpts() ListOptions {
-	l := ListOptions{}
-	l.Limit = -1
-	return l
-}
+// Total represents the totals of a region computed in Sum
+type Total struct {
+	Meta `bson:",inline"`
 
-// IsValidKey checks if a string is in an array
-func IsValidKey(str string, list []string) bool {
-	for _, s := range list {
-		if s == str {
-			return true
-		}
-	}
-	return false
+	TotalCases     *int64 `bson:"total_cases" json:"total_cases"`
+	TotalDeaths    *int64 `bson:"total_deaths" json:"total_deaths"`
+	TotalRecovered *int64 `bson:"total_recovered" json:"total_recovered"`
+	TotalActive    *int64 `bson:"total_active" json:"total_active"`
+	TotalCritical  *int64 `bson:"total_critical" json:"total_critical"`
+
+	Cases     *int64 `bson:"cases" json:"cases"`
+	Deaths    *int64 `bson:"deaths" json:"deaths"`
+	Recovered *int64 `bson:"recovered" json:"recovered"`
 }
+
+// Location implements dataset.Located
+func (r *Record) Location() *dataset.Point { return r.Loc }
+
+// SetDistance implements dataset.Located
+func (r *Record) SetDistance(km float64) { r.Distance = &km }
+
+// Location implements dataset.Located
+func (m *Meta) Location() *dataset.Point { return m.Loc }
+
+// SetDistance implements dataset.Located
+func (m *Meta) SetDistance(km float64) { m.Distance = &km }
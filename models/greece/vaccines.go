@@ -0,0 +1,51 @@
+package greece
+
+import (
+	"github.com/cvcio/covid-19-api/models/dataset"
+	"github.com/cvcio/covid-19-api/models/gr_vaccines"
+)
+
+// Vaccines joins the vaccinations of the gr_vaccines collection to the
+// regional units, served under `/join/greece/vaccines`
+var Vaccines = &dataset.Join{
+	Name:     "vaccines",
+	Dataset:  gr_vaccines.Dataset,
+	Match:    "region",
+	Areas:    vaccineAreas,
+	Keys:     []string{"new_cases", "incidence_7d"},
+	JoinKeys: []string{"coverage_dose_1", "coverage_full", "coverage_booster"},
+	Correlate: []dataset.Pair{
+		{X: "incidence_7d", Y: "coverage_dose_1"},
+		{X: "incidence_7d", Y: "coverage_full"},
+	},
+}
+
+// vaccineAreas maps the gr_vaccines regions to the greece regions where
+// they differ. Vaccinations are reported by the regional units of 2011,
+// while cases are reported by the former prefectures of the islands, so
+// the vaccinations of their units are merged
+var vaccineAreas = map[string]string{
+	// Cyclades
+	"Andros":      "Cyclades",
+	"Kea-Kythnos": "Cyclades",
+	"Milos":       "Cyclades",
+	"Mykonos":     "Cyclades",
+	"Naxos":       "Cyclades",
+	"Paros":       "Cyclades",
+	"Syros":       "Cyclades",
+	"Thira":       "Cyclades",
+	"Tinos":       "Cyclades",
+	// Dodecanese
+	"Kalymnos":  "Dodecanese",
+	"Karpathos": "Dodecanese",
+	"Kos":       "Dodecanese",
+	"Rhodes":    "Dodecanese",
+	// North Aegean
+	"Lemnos": "Lesvos",
+	"Ikaria": "Samos",
+	// Ionian Islands
+	"Ithaca": "Kefalonia",
+	// Thasos and the Sporades
+	"Thasos":   "Kavala",
+	"Sporades": "Magnesia",
+}
@@ -31,6 +31,10 @@ type Config struct {
 		Port string `envconfig:"REDIS_PORT" default:"6379"`
 		Path string `envconfig:"REDIS_PATH" default:"0"`
 	}
+	Legacy struct {
+		Deprecation time.Time `envconfig:"LEGACY_DEPRECATION" default:"2026-11-01T00:00:00Z"`
+		Sunset      time.Time `envconfig:"LEGACY_SUNSET" default:"2027-05-01T00:00:00Z"`
+	}
 	RateLimit struct {
 		Period time.Duration `default:"60m" envconfig:"RATE_DURATION"`
 		Limit  int           `default:"1000" envconfig:"RATE_LIMIT"`
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+
+	"github.com/cvcio/covid-19-api/pkg/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Find implements store.Store using a mongo find command
+func (db *DB) Find(ctx context.Context, collName string, q store.Query) (store.Cursor, error) {
+	// set projection fields
+	projection := bson.D{{Key: "_id", Value: 0}}
+	for _, key := range q.Keys {
+		projection = append(projection, bson.E{Key: key, Value: 1})
+	}
+
+	// set find options
+	findOptions := options.Find().SetSort(sort(q.Sort)).SetProjection(projection)
+	if q.Skip > 0 {
+		findOptions.SetSkip(int64(q.Skip))
+	}
+	if q.Limit > 0 {
+		findOptions.SetLimit(int64(q.Limit))
+	}
+
+	var c *mongo.Cursor
+	f := func(collection *mongo.Collection) error {
+		var err error
+		c, err = collection.Find(ctx, filter(q), findOptions)
+		return err
+	}
+
+	if err := db.Execute(collName, f); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Aggregate implements store.Store using a mongo aggregation pipeline
+func (db *DB) Aggregate(ctx context.Context, collName string, q store.Query, g store.Group) (store.Cursor, error) {
+	// set group fields
+	group := bson.D{{Key: "_id", Value: nil}}
+	if g.By != "" {
+		group[0].Value = "$" + g.By
+	}
+	groupSort := []string{g.Sort}
+	if g.Interval != "" {
+		group[0].Value = bson.D{{Key: "by", Value: "$" + g.By}, {Key: "bucket", Value: bucket(g.Interval)}}
+		groupSort = append([]string{"_id.bucket"}, groupSort...)
+	}
+	// sums of keys no document holds a number of are set to null,
+	// counting the numbers summed
+	var counted, hidden bson.D
+	for _, f := range g.Fields {
+		var value interface{} = "$" + f.Key
+		if f.Op == store.Push {
+			// missing keys are pushed as null, so that the series
+			// line up with the pushed dates
+			value = bson.D{{Key: "$ifNull", Value: bson.A{"$" + f.Key, nil}}}
+		}
+		group = append(group, bson.E{Key: f.Name, Value: bson.D{{Key: f.Op, Value: value}}})
+		if f.Op == store.Sum {
+			count := "_n_" + f.Name
+			group = append(group, bson.E{Key: count, Value: bson.D{{Key: store.Sum, Value: isNumber(f.Key)}}})
+			counted = append(counted, bson.E{Key: f.Name, Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$" + count, 0}}}, "$" + f.Name, nil,
+			}}}})
+			hidden = append(hidden, bson.E{Key: count, Value: 0})
+		}
+	}
+
+	// set aggregation pipeline, documents are sorted before grouping
+	// so $first and $last accumulators follow the query sort
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter(q)}}}
+	if len(q.Sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort(q.Sort)}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: group}})
+	if s := sort(groupSort); len(s) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: s}})
+	}
+	if len(counted) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: counted}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: append(bson.D{{Key: "_id", Value: 0}}, hidden...)}})
+
+	var c *mongo.Cursor
+	f := func(collection *mongo.Collection) error {
+		var err error
+		c, err = collection.Aggregate(ctx, pipeline, options.Aggregate())
+		return err
+	}
+
+	if err := db.Execute(collName, f); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// EnsureGeoIndex implements store.Indexer, creating a 2dsphere index
+func (db *DB) EnsureGeoIndex(ctx context.Context, collName, field string) error {
+	return db.Execute(collName, func(collection *mongo.Collection) error {
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: field, Value: "2dsphere"}},
+		})
+		return err
+	})
+}
+
+// filter builds the mongo query from a store query
+func filter(q store.Query) bson.M {
+	filter := bson.M{}
+
+	// set id filter
+	var idQuery bson.D
+	if len(q.IDs) == 1 && len(q.Exclude) == 0 {
+		filter[q.Field] = q.IDs[0]
+	} else if len(q.IDs) > 0 {
+		idQuery = append(idQuery, bson.E{Key: "$in", Value: q.IDs})
+	}
+	if len(q.Exclude) > 0 {
+		idQuery = append(idQuery, bson.E{Key: "$nin", Value: q.Exclude})
+	}
+	if len(idQuery) > 0 {
+		filter[q.Field] = idQuery
+	}
+
+	// build date limit query
+	var dateQuery bson.D
+	if !q.From.IsZero() {
+		dateQuery = append(dateQuery, bson.E{Key: "$gte", Value: q.From})
+	}
+	if !q.To.IsZero() {
+		dateQuery = append(dateQuery, bson.E{Key: "$lte", Value: q.To})
+	}
+	if len(dateQuery) > 0 {
+		filter["date"] = dateQuery
+	}
+
+	// set spatial filter
+	if q.Geo != nil {
+		var geometry interface{}
+		if len(q.Geo.Polygon) > 0 {
+			geometry = bson.M{"$geometry": bson.M{
+				"type":        "Polygon",
+				"coordinates": bson.A{q.Geo.Polygon},
+			}}
+		} else {
+			geometry = bson.M{"$centerSphere": bson.A{q.Geo.Center, q.Geo.Radius / store.EarthRadius}}
+		}
+		filter[q.Geo.Field] = bson.M{"$geoWithin": geometry}
+	}
+
+	// continue after the position, following the date and id sort
+	if q.After != nil {
+		filter["$or"] = bson.A{
+			bson.M{"date": bson.M{"$gt": q.After.Date}},
+			bson.M{"date": q.After.Date, q.Field: bson.M{"$gt": q.After.ID}},
+		}
+	}
+
+	return filter
+}
+
+// bucket builds the expression of the interval bucket of the document
+// date, see store.Bucket
+func bucket(interval string) bson.D {
+	date := func(op string) bson.D {
+		return bson.D{{Key: op, Value: "$date"}}
+	}
+
+	var parts bson.D
+	switch interval {
+	case store.Week:
+		// $dateFromParts defaults to the monday of the ISO week
+		parts = bson.D{{Key: "isoWeekYear", Value: date("$isoWeekYear")}, {Key: "isoWeek", Value: date("$isoWeek")}}
+	case store.Month:
+		parts = bson.D{{Key: "year", Value: date("$year")}, {Key: "month", Value: date("$month")}}
+	default:
+		parts = bson.D{{Key: "year", Value: date("$year")}, {Key: "month", Value: date("$month")}, {Key: "day", Value: date("$dayOfMonth")}}
+	}
+	return bson.D{{Key: "$dateFromParts", Value: parts}}
+}
+
+// isNumber builds the expression of 1 if the document key holds a
+// number, 0 otherwise
+func isNumber(key string) bson.D {
+	numeric := bson.D{{Key: "$in", Value: bson.A{bson.D{{Key: "$type", Value: "$" + key}}, bson.A{"int", "long", "double", "decimal"}}}}
+	return bson.D{{Key: "$cond", Value: bson.A{numeric, 1, 0}}}
+}
+
+// sort builds an ascending mongo sort document
+func sort(keys []string) bson.D {
+	sort := bson.D{}
+	for _, key := range keys {
+		if key != "" {
+			sort = append(sort, bson.E{Key: key, Value: 1})
+		}
+	}
+	return sort
+}
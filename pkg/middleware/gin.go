@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"time"
@@ -14,9 +15,9 @@ func EnableCORS(cors string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", cors)
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "X-Requested-With, Content-Type, Origin, Accept, Client-Security-Token, Accept-Encoding, Authorization")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Authorization")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Authorization, Link, X-Next-Cursor, X-Data-Date, Deprecation, Sunset")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -27,6 +28,27 @@ func EnableCORS(cors string) gin.HandlerFunc {
 	}
 }
 
+// Deprecated : Mark the routes deprecated in favor of the successor
+// version, with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers
+func Deprecated(deprecation, sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !deprecation.IsZero() {
+			c.Writer.Header().Set("Deprecation", "@"+fmt.Sprint(deprecation.Unix()))
+		}
+		if !sunset.IsZero() {
+			c.Writer.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		// links are joined in a single value, the cached responses
+		// replay the last value of each header only
+		link := fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successor, c.Request.URL.RequestURI())
+		if prev := c.Writer.Header().Get("Link"); prev != "" {
+			link = prev + ", " + link
+		}
+		c.Writer.Header().Set("Link", link)
+		c.Next()
+	}
+}
+
 type loggerEntryWithFields interface {
 	WithFields(fields logrus.Fields) *logrus.Entry
 }
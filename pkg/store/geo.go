@@ -0,0 +1,69 @@
+package store
+
+import (
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Distance returns the great-circle distance in kilometers between
+// two [lon, lat] positions
+func Distance(a, b []float64) float64 {
+	lon1, lat1 := radians(a[0]), radians(a[1])
+	lon2, lat2 := radians(b[0]), radians(b[1])
+
+	h := math.Pow(math.Sin((lat2-lat1)/2), 2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lon2-lon1)/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// within checks if the GeoJSON point of a document matches the filter
+func within(doc bson.M, g *Geo) bool {
+	point, ok := coordinates(doc[g.Field])
+	if !ok {
+		return false
+	}
+	if len(g.Polygon) > 0 {
+		return inPolygon(point, g.Polygon)
+	}
+	return Distance(g.Center, point) <= g.Radius
+}
+
+// coordinates returns the [lon, lat] position of a GeoJSON point
+func coordinates(v interface{}) ([]float64, bool) {
+	var point bson.M
+	switch p := v.(type) {
+	case bson.M:
+		point = p
+	case bson.D:
+		point = p.Map()
+	default:
+		return nil, false
+	}
+	list, ok := point["coordinates"].(bson.A)
+	if !ok || len(list) != 2 {
+		return nil, false
+	}
+	lon, ok1 := toFloat(list[0])
+	lat, ok2 := toFloat(list[1])
+	return []float64{lon, lat}, ok1 && ok2
+}
+
+// inPolygon checks if a position is inside a ring using ray casting,
+// treating edges as straight lines in lon/lat
+func inPolygon(p []float64, ring [][]float64) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// radians converts degrees to radians
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
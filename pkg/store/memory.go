@@ -0,0 +1,411 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Memory is an in-memory Store holding fixture documents, it implements
+// the subset of the mongo query semantics used by the datasets
+type Memory struct {
+	mu          sync.RWMutex
+	collections map[string][]bson.M
+}
+
+// NewMemory creates a new empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		collections: make(map[string][]bson.M),
+	}
+}
+
+// Load adds documents to a collection, documents can be any value
+// that marshals to a bson document
+func (m *Memory) Load(collection string, docs ...interface{}) error {
+	list := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		b, err := bson.Marshal(doc)
+		if err != nil {
+			return errors.Wrapf(err, "memory.%s.load()", collection)
+		}
+		var entry bson.M
+		if err := bson.Unmarshal(b, &entry); err != nil {
+			return errors.Wrapf(err, "memory.%s.load()", collection)
+		}
+		list = append(list, entry)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collections[collection] = append(m.collections[collection], list...)
+	return nil
+}
+
+// LoadJSON adds documents to a collection from a JSON array in mongo
+// extended JSON format, e.g. as exported with `mongoexport --jsonArray`
+func (m *Memory) LoadJSON(collection string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrapf(err, "memory.%s.load()", collection)
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrapf(err, "memory.%s.load()", collection)
+	}
+
+	docs := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(r, false, &doc); err != nil {
+			return errors.Wrapf(err, "memory.%s.load()", collection)
+		}
+		docs = append(docs, doc)
+	}
+
+	return m.Load(collection, docs...)
+}
+
+// Find implements Store
+func (m *Memory) Find(ctx context.Context, collection string, q Query) (Cursor, error) {
+	docs := m.match(collection, q)
+	if q.After != nil {
+		docs = after(docs, q.Field, q.After)
+	}
+	if q.Skip > 0 {
+		if q.Skip > len(docs) {
+			q.Skip = len(docs)
+		}
+		docs = docs[q.Skip:]
+	}
+	if q.Limit > 0 && len(docs) > q.Limit {
+		docs = docs[:q.Limit]
+	}
+
+	list := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		list = append(list, project(doc, q.Keys))
+	}
+
+	return &memoryCursor{docs: list, pos: -1}, nil
+}
+
+// Aggregate implements Store
+func (m *Memory) Aggregate(ctx context.Context, collection string, q Query, g Group) (Cursor, error) {
+	var (
+		keys    []string
+		groups  = make(map[string]bson.M)
+		buckets = make(map[string]time.Time)
+	)
+
+	for _, doc := range m.match(collection, q) {
+		id := fmt.Sprint(doc[g.By])
+		var b time.Time
+		if g.Interval != "" {
+			date, _ := toTime(doc["date"])
+			b = Bucket(date, g.Interval)
+			id += "/" + b.Format(time.RFC3339)
+		}
+		group, ok := groups[id]
+		if !ok {
+			group = bson.M{}
+			groups[id] = group
+			buckets[id] = b
+			keys = append(keys, id)
+		}
+
+		for _, f := range g.Fields {
+			v, exists := doc[f.Key]
+			switch f.Op {
+			case First:
+				if !ok {
+					group[f.Name] = v
+				}
+			case Last:
+				group[f.Name] = v
+			case Sum:
+				group[f.Name] = add(group[f.Name], v)
+			case Max:
+				max := group[f.Name]
+				if v != nil && (max == nil || compare(v, max) > 0) {
+					max = v
+				}
+				group[f.Name] = max
+			case Push:
+				// missing keys are pushed as null, as $ifNull does
+				list, _ := group[f.Name].(bson.A)
+				group[f.Name] = append(list, v)
+			case AddToSet:
+				list, _ := group[f.Name].(bson.A)
+				if list == nil {
+					list = bson.A{}
+				}
+				if exists && !contains(list, v) {
+					list = append(list, v)
+				}
+				group[f.Name] = list
+			default:
+				return nil, fmt.Errorf("memory.%s.aggregate(): unsupported accumulator %s", collection, f.Op)
+			}
+		}
+	}
+
+	// groups are sorted by bucket, then by the Sort key
+	sort.SliceStable(keys, func(i, j int) bool {
+		if a, b := buckets[keys[i]], buckets[keys[j]]; !a.Equal(b) {
+			return a.Before(b)
+		}
+		return g.Sort != "" && compare(groups[keys[i]][g.Sort], groups[keys[j]][g.Sort]) < 0
+	})
+	list := make([]bson.M, 0, len(keys))
+	for _, id := range keys {
+		list = append(list, groups[id])
+	}
+
+	return &memoryCursor{docs: list, pos: -1}, nil
+}
+
+// match returns the sorted documents of a collection matching the query
+func (m *Memory) match(collection string, q Query) []bson.M {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var docs []bson.M
+	for _, doc := range m.collections[collection] {
+		if len(q.IDs) > 0 || len(q.Exclude) > 0 {
+			id, _ := doc[q.Field].(string)
+			if len(q.IDs) > 0 && !containsString(q.IDs, id) {
+				continue
+			}
+			if containsString(q.Exclude, id) {
+				continue
+			}
+		}
+		if q.Geo != nil && !within(doc, q.Geo) {
+			continue
+		}
+		if !q.From.IsZero() || !q.To.IsZero() {
+			date, ok := toTime(doc["date"])
+			if !ok {
+				continue
+			}
+			if !q.From.IsZero() && date.Before(q.From) {
+				continue
+			}
+			if !q.To.IsZero() && date.After(q.To) {
+				continue
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, key := range q.Sort {
+			if c := compare(docs[i][key], docs[j][key]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	return docs
+}
+
+// after returns the documents sorted after the position
+func after(docs []bson.M, field string, p *Position) []bson.M {
+	var list []bson.M
+	for _, doc := range docs {
+		date, _ := toTime(doc["date"])
+		id, _ := doc[field].(string)
+		if date.Before(p.Date) || date.Equal(p.Date) && id <= p.ID {
+			continue
+		}
+		list = append(list, doc)
+	}
+	return list
+}
+
+// project returns a copy of the document with the requested keys only
+func project(doc bson.M, keys []string) bson.M {
+	entry := bson.M{}
+	for k, v := range doc {
+		if k == "_id" {
+			continue
+		}
+		if len(keys) > 0 && !containsString(keys, k) {
+			continue
+		}
+		entry[k] = v
+	}
+	return entry
+}
+
+// memoryCursor iterates over in-memory documents
+type memoryCursor struct {
+	docs []bson.M
+	pos  int
+	err  error
+}
+
+// Next implements Cursor
+func (c *memoryCursor) Next(ctx context.Context) bool {
+	if err := ctx.Err(); err != nil {
+		c.err = err
+		return false
+	}
+	c.pos++
+	return c.pos < len(c.docs)
+}
+
+// Decode implements Cursor
+func (c *memoryCursor) Decode(val interface{}) error {
+	if c.pos < 0 || c.pos >= len(c.docs) {
+		return errors.New("memory cursor: no current document")
+	}
+	b, err := bson.Marshal(c.docs[c.pos])
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(b, val)
+}
+
+// Err implements Cursor
+func (c *memoryCursor) Err() error {
+	return c.err
+}
+
+// Close implements Cursor
+func (c *memoryCursor) Close(ctx context.Context) error {
+	c.docs = nil
+	return nil
+}
+
+// add sums two numeric values the way $sum does, ignoring
+// non-numeric values, nil until a number is added
+func add(sum, v interface{}) interface{} {
+	switch n := v.(type) {
+	case int32:
+		v = int64(n)
+	case int64, float64:
+	default:
+		return sum
+	}
+	if sum == nil {
+		return v
+	}
+	if a, ok := sum.(int64); ok {
+		if b, ok := v.(int64); ok {
+			return a + b
+		}
+	}
+	a, _ := toFloat(sum)
+	b, _ := toFloat(v)
+	return a + b
+}
+
+// compare orders two bson values, nulls first, then numbers,
+// strings and dates
+func compare(a, b interface{}) int {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch a.(type) {
+	case nil:
+		return 0
+	case string:
+		return strings.Compare(a.(string), b.(string))
+	}
+	if x, ok := toFloat(a); ok {
+		y, _ := toFloat(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	if x, ok := toTime(a); ok {
+		y, _ := toTime(b)
+		switch {
+		case x.Before(y):
+			return -1
+		case x.After(y):
+			return 1
+		}
+	}
+	return 0
+}
+
+// rank returns the sort order of a value type
+func rank(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+	if _, ok := toFloat(v); ok {
+		return 1
+	}
+	if _, ok := v.(string); ok {
+		return 2
+	}
+	if _, ok := toTime(v); ok {
+		return 4
+	}
+	return 3
+}
+
+// toFloat converts a bson number to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// toTime converts a bson date to time.Time
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case primitive.DateTime:
+		return t.Time(), true
+	case time.Time:
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// contains checks if a value is in a bson array
+func contains(list bson.A, v interface{}) bool {
+	for _, s := range list {
+		if reflect.DeepEqual(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString checks if a string is in an array
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
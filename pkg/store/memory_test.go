@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// testDocs loads daily documents of the ids, from 2021-03-01
+func testDocs(t *testing.T, ids ...string) *Memory {
+	t.Helper()
+
+	m := NewMemory()
+	for day := 0; day < 3; day++ {
+		for i, id := range ids {
+			err := m.Load("test", bson.M{
+				"date":   time.Date(2021, 3, 1+day, 0, 0, 0, 0, time.UTC),
+				"id":     id,
+				"cases":  int64(10*day + i),
+				"source": "src" + id,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return m
+}
+
+// testEntry is a decoded test document
+type testEntry struct {
+	Date  time.Time `bson:"date"`
+	ID    string    `bson:"id"`
+	Cases *int64    `bson:"cases"`
+}
+
+// readAll decodes all documents of a cursor
+func readAll(t *testing.T, c Cursor) []testEntry {
+	t.Helper()
+
+	var list []testEntry
+	for c.Next(context.Background()) {
+		var e testEntry
+		if err := c.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, e)
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestMemoryFind(t *testing.T) {
+	m := testDocs(t, "B", "A", "C")
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"all sorted", Query{Sort: []string{"date", "id"}}, "A1,B1,C1,A2,B2,C2,A3,B3,C3"},
+		{"ids", Query{Field: "id", IDs: []string{"A", "C"}, Sort: []string{"date", "id"}}, "A1,C1,A2,C2,A3,C3"},
+		{"exclude", Query{Field: "id", Exclude: []string{"A"}, Sort: []string{"date", "id"}}, "B1,C1,B2,C2,B3,C3"},
+		{"date range", Query{
+			From: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2021, 3, 2, 23, 0, 0, 0, time.UTC),
+			Sort: []string{"date", "id"},
+		}, "A2,B2,C2"},
+		{"skip and limit", Query{Sort: []string{"date", "id"}, Skip: 2, Limit: 3}, "C1,A2,B2"},
+		{"after", Query{
+			Field: "id",
+			Sort:  []string{"date", "id"},
+			After: &Position{Date: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC), ID: "B"},
+		}, "C2,A3,B3,C3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := m.Find(context.Background(), "test", tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range readAll(t, c) {
+				got = append(got, e.ID+e.Date.Format("2"))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("got %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryFindKeys(t *testing.T) {
+	m := testDocs(t, "A")
+
+	c, err := m.Find(context.Background(), "test", Query{Keys: []string{"id"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := readAll(t, c)
+	if len(list) != 1 || list[0].ID != "A" || list[0].Cases != nil || !list[0].Date.IsZero() {
+		t.Errorf("got %+v, want the id only", list)
+	}
+}
+
+func TestMemoryAggregate(t *testing.T) {
+	m := testDocs(t, "B", "A")
+	// missing keys are pushed as null
+	if err := m.Load("test", bson.M{"date": time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), "id": "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := m.Aggregate(context.Background(), "test", Query{Sort: []string{"date"}}, Group{
+		By: "id",
+		Fields: []Field{
+			{Name: "id", Op: First, Key: "id"},
+			{Name: "first", Op: First, Key: "cases"},
+			{Name: "last", Op: Last, Key: "cases"},
+			{Name: "sum", Op: Sum, Key: "cases"},
+			{Name: "unreported", Op: Sum, Key: "tests"},
+			{Name: "max", Op: Max, Key: "cases"},
+			{Name: "cases", Op: Push, Key: "cases"},
+			{Name: "sources", Op: AddToSet, Key: "source"},
+		},
+		Sort: "id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []bson.M
+	for c.Next(context.Background()) {
+		var e bson.M
+		if err := c.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+
+	// sums of keys never reported are null
+	want := []bson.M{
+		{"id": "A", "first": int64(1), "last": nil, "sum": int64(33), "unreported": nil, "max": int64(21), "cases": bson.A{int64(1), int64(11), int64(21), nil}, "sources": bson.A{"srcA"}},
+		{"id": "B", "first": int64(0), "last": int64(20), "sum": int64(30), "unreported": nil, "max": int64(20), "cases": bson.A{int64(0), int64(10), int64(20)}, "sources": bson.A{"srcB"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMemoryAggregateInterval(t *testing.T) {
+	m := testDocs(t, "B", "A")
+
+	tests := []struct {
+		interval string
+		want     string
+	}{
+		{Day, "A1:1,B1:0,A2:11,B2:10,A3:21,B3:20"},
+		{Week, "A1:33,B1:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			c, err := m.Aggregate(context.Background(), "test", Query{Sort: []string{"date"}}, Group{
+				By: "id",
+				Fields: []Field{
+					{Name: "id", Op: First, Key: "id"},
+					{Name: "date", Op: First, Key: "date"},
+					{Name: "cases", Op: Sum, Key: "cases"},
+				},
+				Sort:     "id",
+				Interval: tt.interval,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range readAll(t, c) {
+				got = append(got, fmt.Sprintf("%s%s:%d", e.ID, e.Date.Format("2"), *e.Cases))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("got %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
+
+func TestBucket(t *testing.T) {
+	// a wednesday
+	date := time.Date(2021, 3, 17, 15, 4, 5, 0, time.UTC)
+	tests := map[string]time.Time{
+		Day:   time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC),
+		Week:  time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+		Month: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for interval, want := range tests {
+		if got := Bucket(date, interval); !got.Equal(want) {
+			t.Errorf("%s bucket = %v, want %v", interval, got, want)
+		}
+	}
+}
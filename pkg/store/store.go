@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Store is implemented by the storage backends serving the datasets.
+// The MongoDB implementation lives in pkg/db, and Memory holds fixture
+// documents in memory so models can run without a database.
+type Store interface {
+	// Find returns the documents matching the query
+	Find(ctx context.Context, collection string, q Query) (Cursor, error)
+	// Aggregate groups the documents matching the query
+	Aggregate(ctx context.Context, collection string, q Query, g Group) (Cursor, error)
+}
+
+// Indexer is implemented by stores that need indexes created
+// for the queries they serve
+type Indexer interface {
+	// EnsureGeoIndex creates a 2dsphere index on the field if missing
+	EnsureGeoIndex(ctx context.Context, collection, field string) error
+}
+
+// Cursor iterates over the documents returned by a Store,
+// it is satisfied by *mongo.Cursor
+type Cursor interface {
+	Next(ctx context.Context) bool
+	Decode(val interface{}) error
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// Query represents the filter, projection and sort of a Find
+// or the match stage of an Aggregate
+type Query struct {
+	// Field is the document key matched against IDs
+	Field string
+	// IDs lists the accepted values of Field, empty matches all
+	IDs []string
+	// Exclude lists the rejected values of Field
+	Exclude []string
+	// From and To limit the document date, zero values are ignored
+	From time.Time
+	To   time.Time
+	// Keys lists the document keys to return, empty returns all
+	Keys []string
+	// Sort lists the document keys to sort by in ascending order
+	Sort []string
+	// Geo limits the documents by location, nil matches all
+	Geo *Geo
+	// After skips the documents sorted up to and including the
+	// position, nil starts from the first document
+	After *Position
+	// Skip and Limit page the documents returned by Find,
+	// a zero Limit returns all
+	Skip  int
+	Limit int
+}
+
+// Geo represents a spatial filter on a GeoJSON point field, matching
+// the documents within the circle or within the polygon
+type Geo struct {
+	// Field is the document key of the GeoJSON point
+	Field string
+	// Center is the [lon, lat] center of the circle and Radius
+	// its radius in kilometers
+	Center []float64
+	Radius float64
+	// Polygon is a closed ring of [lon, lat] positions
+	Polygon [][]float64
+}
+
+// EarthRadius is the mean radius of the earth in kilometers
+const EarthRadius = 6371.0
+
+// Position represents the sort keys of a document in a Find sorted
+// by date and Field, used to continue listing after the document
+type Position struct {
+	Date time.Time
+	ID   string
+}
+
+// Field represents a single accumulator of a group stage,
+// e.g. `total_cases: { $last: "$cases" }`
+type Field struct {
+	Name string
+	Op   string
+	Key  string
+}
+
+// Group represents a group stage, grouping documents by the By key,
+// or all documents together if empty, and sorting the groups by the
+// Sort key
+type Group struct {
+	By     string
+	Fields []Field
+	Sort   string
+	// Interval groups the documents by the interval bucket of their
+	// date too, the groups are sorted by bucket before the Sort key.
+	// Empty groups by the By key only
+	Interval string
+}
+
+// Group intervals
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+)
+
+// Bucket returns the start of the interval bucket of a date, the
+// monday of its ISO week or the first day of its month
+func Bucket(t time.Time, interval string) time.Time {
+	year, month, day := t.Date()
+	switch interval {
+	case Week:
+		// ISO weeks start on monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case Month:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Supported group accumulators, Push pushes missing keys as null so
+// that the pushed series line up, Max ignores missing keys and Sum is
+// null if no document holds a number, rather than zero as in $sum
+const (
+	First    = "$first"
+	Last     = "$last"
+	Sum      = "$sum"
+	Max      = "$max"
+	Push     = "$push"
+	AddToSet = "$addToSet"
+)